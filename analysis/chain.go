@@ -0,0 +1,92 @@
+// Package analysis derives the performance of the validators from the cached or fetched beacon chain data
+package analysis
+
+import (
+	"beaconchain/types"
+	"fmt"
+)
+
+// BlockSource provides all blocks of the epoch, canonical or not
+type BlockSource interface {
+	GetEpochBlocks(epoch uint64) ([]*types.Block, error)
+}
+
+// CanonicalChain resolves the canonical blocks of the slots,
+// the blocks of the source are requested by epochs, once per epoch
+type CanonicalChain struct {
+	source BlockSource
+	blocks map[uint64][]*types.Block
+	// canonical blocks by slot of the requested epochs
+	canonical map[uint64]*types.Block
+}
+
+// NewCanonicalChain returns the chain of the blocks of the source
+func NewCanonicalChain(source BlockSource) *CanonicalChain {
+	return &CanonicalChain{
+		source:    source,
+		blocks:    make(map[uint64][]*types.Block),
+		canonical: make(map[uint64]*types.Block),
+	}
+}
+
+// Blocks returns all blocks of the epoch
+func (c *CanonicalChain) Blocks(epoch uint64) ([]*types.Block, error) {
+	if blocks, ok := c.blocks[epoch]; ok {
+		return blocks, nil
+	}
+	blocks, err := c.source.GetEpochBlocks(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("blocks of epoch %d: %w", epoch, err)
+	}
+	c.blocks[epoch] = blocks
+	for _, block := range blocks {
+		if block.Canonical {
+			c.canonical[block.Slot] = block
+		}
+	}
+	return blocks, nil
+}
+
+// BlockAt returns the canonical block of the slot, nil for the missed slot
+func (c *CanonicalChain) BlockAt(slot uint64) (*types.Block, error) {
+	if _, err := c.Blocks(slot / types.SlotsPerEpoch); err != nil {
+		return nil, err
+	}
+	return c.canonical[slot], nil
+}
+
+// RootAt returns the root of the latest canonical block at or before the slot,
+// which is the root the slot votes for, nil when there is no such block
+func (c *CanonicalChain) RootAt(slot uint64) ([]byte, error) {
+	for {
+		block, err := c.BlockAt(slot)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			return block.BlockRoot, nil
+		}
+		if slot == 0 {
+			return nil, nil
+		}
+		slot--
+	}
+}
+
+// ForkTree returns the tree of the blocks of the epoch, canonical or not, linked by the parent roots
+func ForkTree(source BlockSource, epoch uint64) (*types.ForkTree, error) {
+	return BlockTree(source, epoch*types.SlotsPerEpoch, (epoch+1)*types.SlotsPerEpoch-1)
+}
+
+// BlockTree returns the tree of the blocks of the range of slots, inclusive
+func BlockTree(source BlockSource, fromSlot, toSlot uint64) (*types.ForkTree, error) {
+	blocks := make([]*types.Block, 0)
+	for epoch := fromSlot / types.SlotsPerEpoch; epoch <= toSlot/types.SlotsPerEpoch; epoch++ {
+		epochBlocks, err := source.GetEpochBlocks(epoch)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, epochBlocks...)
+	}
+	return types.NewForkTree(fromSlot, toSlot, blocks), nil
+}
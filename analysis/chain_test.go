@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"bytes"
+	"testing"
+)
+
+func TestForkTree(t *testing.T) {
+	client, node := rpctest.NewSource(t, fakenode.Config{
+		Validators:    100,
+		Epochs:        4,
+		OrphanedSlots: []uint64{66},
+	})
+	if _, err := client.GetEpochBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+	oldRoot := node.Chain.CanonicalRoot(70)
+	node.Reorg(70)
+	if err := client.InvalidateEpoch(2); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := ForkTree(client, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first block of the epoch is built on the previous one
+	if len(tree.Roots) != 1 || tree.Roots[0].Slot != 64 {
+		t.Fatalf("unexpected roots %+v", tree.Roots)
+	}
+	fork, ok := tree.Get(node.Chain.CanonicalRoot(69))
+	if !ok || len(fork.Children) != 2 {
+		t.Fatalf("block 69 is not the fork point %+v", fork)
+	}
+	replaced, ok := tree.Get(oldRoot)
+	if !ok || replaced.Canonical {
+		t.Errorf("replaced block %+v", replaced)
+	}
+	// the orphan of the slot 66, the replaced and the canonical fork
+	heads := tree.Heads()
+	if len(heads) != 3 {
+		t.Fatalf("unexpected heads %+v", heads)
+	}
+	canonical := 0
+	for _, head := range heads {
+		if head.Slot != 95 && (head.Slot != 66 || head.Canonical) {
+			t.Errorf("unexpected head %+v", head)
+		}
+		if head.Canonical {
+			canonical++
+			if !bytes.Equal(head.Root, node.Chain.CanonicalRoot(95)) {
+				t.Errorf("unexpected canonical head %x", head.Root)
+			}
+		}
+	}
+	if canonical != 1 {
+		t.Errorf("%d canonical heads", canonical)
+	}
+}
@@ -0,0 +1,98 @@
+package analysis
+
+import (
+	"beaconchain/types"
+	"bytes"
+	"sort"
+)
+
+// ComputePerformance finds the attestations of the assigned attesters of the epoch in the canonical blocks,
+// which can include them, till the end of the next epoch. Votes are correct when they match the roots
+// of the canonical chain: the source and the target at the first slots of their epochs, the head at the slot
+func ComputePerformance(assignments *types.Assignments, chain *CanonicalChain) (*types.EpochPerformance, error) {
+	epoch := uint64(assignments.Epoch)
+	byIndex := make(map[uint64]*types.AttestationPerformance, assignments.NumAssignments)
+	for slotIndex, assignment := range assignments.Assignments {
+		for _, committee := range assignment.Committees {
+			for _, index := range committee {
+				byIndex[index] = &types.AttestationPerformance{
+					Index: index,
+					Slot:  assignments.FirstSlot + uint64(slotIndex),
+				}
+			}
+		}
+	}
+
+	targetRoot, err := chain.RootAt(epoch * types.SlotsPerEpoch)
+	if err != nil {
+		return nil, err
+	}
+	for slot := epoch*types.SlotsPerEpoch + 1; slot < (epoch+2)*types.SlotsPerEpoch; slot++ {
+		block, err := chain.BlockAt(slot)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			continue
+		}
+		for _, a := range block.Attestations {
+			if a.Data == nil || a.Data.Slot/types.SlotsPerEpoch != epoch || a.Data.Slot >= slot || slot > a.Data.Slot+types.SlotsPerEpoch {
+				continue
+			}
+			correctSource := false
+			if a.Data.Source != nil {
+				sourceRoot, err := chain.RootAt(a.Data.Source.Epoch * types.SlotsPerEpoch)
+				if err != nil {
+					return nil, err
+				}
+				correctSource = bytes.Equal(a.Data.Source.Root, sourceRoot)
+			}
+			correctTarget := a.Data.Target != nil && a.Data.Target.Epoch == epoch && bytes.Equal(a.Data.Target.Root, targetRoot)
+			headRoot, err := chain.RootAt(a.Data.Slot)
+			if err != nil {
+				return nil, err
+			}
+			// the head is not counted without the target, as the spec does
+			correctHead := correctTarget && bytes.Equal(a.Data.BeaconBlockRoot, headRoot)
+
+			for _, index := range a.Attesters {
+				p, ok := byIndex[index]
+				if !ok || p.Slot != a.Data.Slot {
+					continue
+				}
+				if !p.Included() {
+					p.InclusionSlot = slot
+				}
+				p.CorrectSource = p.CorrectSource || correctSource
+				p.CorrectTarget = p.CorrectTarget || correctTarget
+				p.CorrectHead = p.CorrectHead || correctHead
+			}
+		}
+	}
+
+	out := &types.EpochPerformance{
+		Epoch:      epoch,
+		Validators: make([]types.AttestationPerformance, 0, len(byIndex)),
+	}
+	for _, p := range byIndex {
+		out.Validators = append(out.Validators, *p)
+	}
+	sort.Slice(out.Validators, func(i, j int) bool { return out.Validators[i].Index < out.Validators[j].Index })
+	return out, nil
+}
+
+// PerformanceSource provides the data the performance is computed from
+type PerformanceSource interface {
+	BlockSource
+	GetEpochAssignments(epoch uint64) (*types.Assignments, error)
+}
+
+// EpochPerformance computes the attestation performance of the epoch from the source,
+// it is final once the next epoch is finished
+func EpochPerformance(source PerformanceSource, epoch uint64) (*types.EpochPerformance, error) {
+	assignments, err := source.GetEpochAssignments(epoch)
+	if err != nil {
+		return nil, err
+	}
+	return ComputePerformance(assignments, NewCanonicalChain(source))
+}
@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"beaconchain/types"
+	"testing"
+)
+
+// editedBlocks changes copies of the blocks of the source
+type editedBlocks struct {
+	source BlockSource
+	edit   func(block *types.Block)
+}
+
+func (e editedBlocks) GetEpochBlocks(epoch uint64) ([]*types.Block, error) {
+	blocks, err := e.source.GetEpochBlocks(epoch)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*types.Block, 0, len(blocks))
+	for _, block := range blocks {
+		edited := *block
+		e.edit(&edited)
+		res = append(res, &edited)
+	}
+	return res, nil
+}
+
+func TestEpochPerformance(t *testing.T) {
+	client, _ := rpctest.NewSource(t, fakenode.Config{
+		Validators:    200,
+		Epochs:        5,
+		MissedSlots:   []uint64{70},
+		OrphanedSlots: []uint64{80},
+	})
+
+	performance, err := EpochPerformance(client, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if performance.Epoch != 2 || len(performance.Validators) != 200 {
+		t.Fatalf("epoch %d performance of %d validators", performance.Epoch, len(performance.Validators))
+	}
+	for _, p := range performance.Validators {
+		expected := uint64(1)
+		if p.Slot == 69 {
+			// the next block is missed
+			expected = 2
+		}
+		if !p.Included() || p.InclusionDistance() != expected || !p.CorrectSource || !p.CorrectTarget || !p.CorrectHead {
+			t.Errorf("validator %d: unexpected performance %+v", p.Index, p)
+		}
+	}
+	summary := performance.Summary()
+	if summary.Included != 200 || summary.CorrectHead != 200 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if _, ok := performance.Get(150); !ok {
+		t.Error("validator 150 is not found")
+	}
+	if _, ok := performance.Get(200); ok {
+		t.Error("validator 200 is found")
+	}
+}
+
+func TestPerformanceIncorrectVotes(t *testing.T) {
+	client, _ := rpctest.NewSource(t, fakenode.Config{Validators: 200, Epochs: 5})
+	source := editedBlocks{source: client, edit: func(block *types.Block) {
+		switch block.Slot {
+		case 66:
+			// attestations of the slot 65 vote for the unknown target
+			attestations := make([]*types.Attestation, 0)
+			for _, a := range block.Attestations {
+				edited, data, target := *a, *a.Data, *a.Data.Target
+				target.Root = make([]byte, 32)
+				data.Target = &target
+				edited.Data = &data
+				attestations = append(attestations, &edited)
+			}
+			block.Attestations = attestations
+		case 68:
+			// attestations of the slot 67 are included by the orphaned block only,
+			// the slot 68 votes for the orphaned head
+			block.Canonical = false
+		}
+	}}
+	assignments, err := client.GetEpochAssignments(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	performance, err := ComputePerformance(assignments, NewCanonicalChain(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range performance.Validators {
+		var ok bool
+		switch p.Slot {
+		case 65:
+			ok = p.Included() && p.CorrectSource && !p.CorrectTarget && !p.CorrectHead
+		case 67:
+			ok = !p.Included() && !p.CorrectSource && !p.CorrectTarget && !p.CorrectHead
+		case 68:
+			ok = p.InclusionDistance() == 1 && p.CorrectSource && p.CorrectTarget && !p.CorrectHead
+		default:
+			ok = p.InclusionDistance() == 1 && p.CorrectSource && p.CorrectTarget && p.CorrectHead
+		}
+		if !ok {
+			t.Errorf("validator %d: unexpected performance %+v", p.Index, p)
+		}
+	}
+}
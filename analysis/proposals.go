@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"beaconchain/types"
+	"sort"
+)
+
+// ProposerStats counts the outcomes of the proposer duties of every assigned validator, sorted by the index
+func ProposerStats(epochs []*types.EpochProposals) []*types.ProposerStats {
+	stats := make(map[uint64]*types.ProposerStats)
+	for _, e := range epochs {
+		for _, p := range e.Slots {
+			s, ok := stats[p.Proposer]
+			if !ok {
+				s = &types.ProposerStats{Index: p.Proposer}
+				stats[p.Proposer] = s
+			}
+			s.Count(p.Status)
+		}
+	}
+	res := make([]*types.ProposerStats, 0, len(stats))
+	for _, s := range stats {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+	return res
+}
+
+// MissedProposals lists the slots of the epochs without a canonical block, missed or orphaned
+func MissedProposals(epochs []*types.EpochProposals) []types.SlotProposal {
+	res := make([]types.SlotProposal, 0)
+	for _, e := range epochs {
+		for _, p := range e.Slots {
+			if p.Status == types.ProposalMissed || p.Status == types.ProposalOrphaned {
+				res = append(res, p)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Slot < res[j].Slot })
+	return res
+}
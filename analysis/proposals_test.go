@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"beaconchain/types"
+	"testing"
+)
+
+func TestProposerStats(t *testing.T) {
+	client, node := rpctest.NewSource(t, fakenode.Config{
+		Validators:  100,
+		Epochs:      4,
+		MissedSlots: []uint64{33, 70},
+	})
+
+	proposals, err := rpc.GetProposals(client, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missed := MissedProposals(proposals)
+	if len(missed) != 2 || missed[0].Slot != 33 || missed[1].Slot != 70 || missed[0].Proposer != node.Chain.Proposer(33) {
+		t.Fatalf("unexpected missed proposals %+v", missed)
+	}
+
+	expected := make(map[uint64]*types.ProposerStats)
+	for slot := uint64(32); slot < 96; slot++ {
+		index := node.Chain.Proposer(slot)
+		if expected[index] == nil {
+			expected[index] = &types.ProposerStats{Index: index}
+		}
+		if slot == 33 || slot == 70 {
+			expected[index].Count(types.ProposalMissed)
+		} else {
+			expected[index].Count(types.ProposalProposed)
+		}
+	}
+	stats := ProposerStats(proposals)
+	if len(stats) != len(expected) {
+		t.Fatalf("stats of %d proposers, expected %d", len(stats), len(expected))
+	}
+	for i, s := range stats {
+		if i > 0 && stats[i-1].Index >= s.Index {
+			t.Errorf("stats are not sorted at %d", i)
+		}
+		if *s != *expected[s.Index] {
+			t.Errorf("validator %d: stats %+v, expected %+v", s.Index, s, expected[s.Index])
+		}
+	}
+}
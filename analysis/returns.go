@@ -0,0 +1,165 @@
+package analysis
+
+import (
+	"beaconchain/types"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ReturnsSource provides the data the returns are computed from
+type ReturnsSource interface {
+	BlockSource
+	GetEpochValidators(epoch uint64) ([]*types.Validator, error)
+	GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error)
+}
+
+// sampleEpochs returns the epochs of the window, where the effective balances are sampled,
+// the ends of the window and the given number of evenly spaced ones between them
+func sampleEpochs(from, to uint64, samples int) []uint64 {
+	res := []uint64{from}
+	for i := 1; i <= samples; i++ {
+		epoch := from + (to-from)*uint64(i)/uint64(samples+1)
+		if epoch != res[len(res)-1] && epoch != to {
+			res = append(res, epoch)
+		}
+	}
+	if to != from {
+		res = append(res, to)
+	}
+	return res
+}
+
+// Returns computes the annualized returns of the validators active within the window of epochs.
+// Every validator is counted since its activation till its exit, its top-ups are excluded
+// from the income. The stake is the effective balance, averaged over the sampled epochs
+func Returns(source ReturnsSource, from, to uint64, samples int) (*types.ReturnsReport, error) {
+	if to <= from {
+		return nil, fmt.Errorf("empty window of epochs %d-%d", from, to)
+	}
+	epochs := sampleEpochs(from, to, samples)
+	sets := make([][]*types.Validator, len(epochs))
+	for i, epoch := range epochs {
+		validators, err := source.GetEpochValidators(epoch)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = validators
+	}
+	first, last := sets[0], sets[len(sets)-1]
+
+	// the effective balances of the epochs the validator was active at
+	stake := make(map[uint64]uint64)
+	samplesOf := make(map[uint64]uint64)
+	for i, validators := range sets {
+		for _, v := range validators {
+			if v.ActivationEpoch <= epochs[i] && epochs[i] < v.ExitEpoch {
+				stake[v.Index] += v.EffectiveBalance
+				samplesOf[v.Index]++
+			}
+		}
+	}
+	startBalances := make(map[uint64]uint64, len(first))
+	for _, v := range first {
+		startBalances[v.Index] = v.Balance
+	}
+
+	returns := make(map[uint64]*types.Return)
+	starts := make(map[uint64]uint64)
+	pubkeys := make(map[string]uint64)
+	activated := make(map[uint64][]uint64)
+	for _, v := range last {
+		start, end := from, to
+		if v.ActivationEpoch > start {
+			start = v.ActivationEpoch
+		}
+		if v.ExitEpoch < end {
+			end = v.ExitEpoch
+		}
+		if end <= start {
+			continue
+		}
+		r := &types.Return{
+			Key:        strconv.FormatUint(v.Index, 10),
+			Validators: 1,
+			EndBalance: v.Balance,
+		}
+		if start == from {
+			r.StartBalance = startBalances[v.Index]
+		} else if v.BalanceActivation > 0 {
+			r.StartBalance = v.BalanceActivation
+		} else {
+			activated[start] = append(activated[start], v.Index)
+		}
+		effective := float64(v.EffectiveBalance)
+		if samplesOf[v.Index] > 0 {
+			effective = float64(stake[v.Index]) / float64(samplesOf[v.Index])
+		}
+		r.StakeEpochs = effective * float64(end-start)
+		returns[v.Index] = r
+		starts[v.Index] = start
+		pubkeys[string(v.PublicKey)] = v.Index
+	}
+
+	// the validators without the balance of the activation window
+	for epoch, indices := range activated {
+		balances, err := source.GetBalancesForEpoch(int64(epoch))
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range indices {
+			returns[index].StartBalance = balances[index]
+		}
+	}
+
+	// deposits of the active validators are top-ups, the ones of the window end are in the next balance
+	chain := NewCanonicalChain(source)
+	for epoch := from; epoch < to; epoch++ {
+		blocks, err := chain.Blocks(epoch)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			if !block.Canonical {
+				continue
+			}
+			for _, d := range block.Deposits {
+				if index, ok := pubkeys[string(d.PublicKey)]; ok && starts[index] <= epoch {
+					returns[index].Deposits += d.Amount
+				}
+			}
+		}
+	}
+
+	report := &types.ReturnsReport{
+		FromEpoch:   from,
+		ToEpoch:     to,
+		Validators:  make([]*types.Return, 0, len(returns)),
+		Credentials: make([]*types.Return, 0),
+		Network:     &types.Return{Key: "network"},
+	}
+	credentials := make(map[string]*types.Return)
+	sorted := make([]*types.Validator, 0, len(returns))
+	for _, v := range last {
+		if _, ok := returns[v.Index]; ok {
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	for _, v := range sorted {
+		r := returns[v.Index]
+		r.Income = int64(r.EndBalance) - int64(r.StartBalance) - int64(r.Deposits)
+		r.Annualize()
+		report.Validators = append(report.Validators, r)
+
+		key := fmt.Sprintf("%x", v.WithdrawalCredentials)
+		if _, ok := credentials[key]; !ok {
+			credentials[key] = &types.Return{Key: key}
+			report.Credentials = append(report.Credentials, credentials[key])
+		}
+		credentials[key].Add(r)
+		report.Network.Add(r)
+	}
+	sort.Slice(report.Credentials, func(i, j int) bool { return report.Credentials[i].Key < report.Credentials[j].Key })
+	return report, nil
+}
@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"beaconchain/types"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// toppedUp adds the deposits to the blocks of the client
+type toppedUp struct {
+	*rpc.PrysmClient
+	blocks editedBlocks
+}
+
+func (t toppedUp) GetEpochBlocks(epoch uint64) ([]*types.Block, error) {
+	return t.blocks.GetEpochBlocks(epoch)
+}
+
+func TestSampleEpochs(t *testing.T) {
+	for _, c := range []struct {
+		from, to uint64
+		samples  int
+		expected []uint64
+	}{
+		{2, 10, 3, []uint64{2, 4, 6, 8, 10}},
+		{2, 10, 0, []uint64{2, 10}},
+		{2, 4, 4, []uint64{2, 3, 4}},
+	} {
+		if epochs := sampleEpochs(c.from, c.to, c.samples); !reflect.DeepEqual(epochs, c.expected) {
+			t.Errorf("%d-%d by %d: epochs %v, expected %v", c.from, c.to, c.samples, epochs, c.expected)
+		}
+	}
+}
+
+func TestReturns(t *testing.T) {
+	client, node := rpctest.NewSource(t, fakenode.Config{
+		Validators: 100,
+		Epochs:     12,
+		Deposits:   []uint64{40},
+	})
+	// validator 3 is topped up at the epoch 3
+	source := toppedUp{client, editedBlocks{client, func(block *types.Block) {
+		if block.Slot == 101 {
+			block.Deposits = append(block.Deposits, &types.Deposit{PublicKey: fakenode.PublicKey(3), Amount: 1000000000})
+		}
+	}}}
+
+	report, err := Returns(source, 2, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Validators) != 101 || len(report.Credentials) != 101 {
+		t.Fatalf("returns of %d validators and %d credentials", len(report.Validators), len(report.Credentials))
+	}
+	for i, r := range report.Validators[:100] {
+		if i == 3 {
+			continue
+		}
+		if r.Income != 8*10000 || r.StakeEpochs != 8*fakenode.MaxEffectiveBalance {
+			t.Errorf("validator %s: unexpected return %+v", r.Key, r)
+		}
+	}
+	toppedUp := report.Validators[3]
+	if toppedUp.Deposits != 1000000000 || toppedUp.EndBalance-toppedUp.StartBalance != 8*10000 {
+		t.Errorf("validator 3: unexpected top-up %+v", toppedUp)
+	}
+	deposited := report.Validators[100]
+	start, _ := node.Chain.Balance(100, 5)
+	if deposited.Key != "100" || deposited.StartBalance != start || deposited.Income != 5*10000 || deposited.Deposits != 0 {
+		t.Errorf("validator 100: unexpected return since the activation %+v", deposited)
+	}
+
+	network := report.Network
+	if network.Validators != 101 || network.Income != 100*8*10000-1000000000+5*10000 {
+		t.Errorf("unexpected network return %+v", network)
+	}
+	apr := float64(network.Income) / network.StakeEpochs * types.EpochsPerYear
+	if math.Abs(network.APR-apr) > 1e-12 || math.Abs(network.APY-(math.Pow(1+apr/365, 365)-1)) > 1e-12 {
+		t.Errorf("network APR %v, APY %v", network.APR, network.APY)
+	}
+	var income int64
+	for _, r := range report.Credentials {
+		income += r.Income
+	}
+	if income != network.Income {
+		t.Errorf("credentials income %d, network %d", income, network.Income)
+	}
+}
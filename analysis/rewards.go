@@ -0,0 +1,240 @@
+package analysis
+
+import (
+	"beaconchain/types"
+	"sort"
+)
+
+// Constants of the phase 0 rewards and penalties
+const (
+	BaseRewardFactor            = 64
+	BaseRewardsPerEpoch         = 4
+	ProposerRewardQuotient      = 8
+	MinSlashingPenaltyQuotient  = 128
+	WhistleblowerRewardQuotient = 512
+	EffectiveBalanceIncrement   = 1000000000
+)
+
+// RewardsInput is the data the balance changes of the epoch are attributed with
+type RewardsInput struct {
+	Epoch uint64
+	// Balances are the balances at the epoch, NextBalances are the ones at the next epoch
+	Balances     map[uint64]uint64
+	NextBalances map[uint64]uint64
+	// Validators is the validator set of the epoch
+	Validators []*types.Validator
+	// Performance is the one of the previous epoch, its rewards are applied at the end of the epoch,
+	// nil for the genesis
+	Performance *types.EpochPerformance
+	// Chain provides the blocks of the epoch and the blocks including the attestations of the previous epoch
+	Chain *CanonicalChain
+}
+
+// integerSquareRoot follows integer_squareroot of the spec
+func integerSquareRoot(n uint64) uint64 {
+	x := n
+	y := (x + 1) / 2
+	for y < x {
+		x = y
+		y = (x + n/x) / 2
+	}
+	return x
+}
+
+// slashedIndices returns the validators slashed by the attester slashing, attesting both attestations
+func slashedIndices(s *types.AttesterSlashing) []uint64 {
+	if s.Attestation1 == nil || s.Attestation2 == nil {
+		return nil
+	}
+	second := make(map[uint64]bool, len(s.Attestation2.AttestingIndices))
+	for _, index := range s.Attestation2.AttestingIndices {
+		second[index] = true
+	}
+	res := make([]uint64, 0)
+	for _, index := range s.Attestation1.AttestingIndices {
+		if second[index] {
+			res = append(res, index)
+		}
+	}
+	return res
+}
+
+// ComputeRewards attributes the balance change of every validator since the epoch till the next one.
+// Proposer rewards, deposits and slashing penalties follow the spec, the rest of the change is
+// the attestation reward or the penalties, depending on its sign
+func ComputeRewards(in *RewardsInput) (*types.EpochRewards, error) {
+	effective := make(map[uint64]uint64, len(in.Validators))
+	pubkeys := make(map[string]uint64, len(in.Validators))
+	totalActive := uint64(0)
+	for _, v := range in.Validators {
+		effective[v.Index] = v.EffectiveBalance
+		pubkeys[string(v.PublicKey)] = v.Index
+		if v.ActivationEpoch <= in.Epoch && in.Epoch < v.ExitEpoch {
+			totalActive += v.EffectiveBalance
+		}
+	}
+	if totalActive < EffectiveBalanceIncrement {
+		totalActive = EffectiveBalanceIncrement
+	}
+	sqrtTotal := integerSquareRoot(totalActive)
+
+	breakdowns := make(map[uint64]*types.RewardsBreakdown)
+	get := func(index uint64) *types.RewardsBreakdown {
+		if _, ok := breakdowns[index]; !ok {
+			breakdowns[index] = &types.RewardsBreakdown{}
+		}
+		return breakdowns[index]
+	}
+
+	// the proposer of the earliest inclusion is rewarded for the attestation of the previous epoch
+	if in.Performance != nil {
+		for _, p := range in.Performance.Validators {
+			if !p.Included() || !p.CorrectSource {
+				continue
+			}
+			block, err := in.Chain.BlockAt(p.InclusionSlot)
+			if err != nil {
+				return nil, err
+			}
+			if block == nil {
+				continue
+			}
+			baseReward := effective[p.Index] * BaseRewardFactor / sqrtTotal / BaseRewardsPerEpoch
+			get(block.Proposer).Proposer += int64(baseReward / ProposerRewardQuotient)
+		}
+	}
+
+	// deposits and slashings are processed with the blocks of the epoch,
+	// the validator is slashed once
+	slashed := make(map[uint64]bool)
+	for slot := in.Epoch * types.SlotsPerEpoch; slot < (in.Epoch+1)*types.SlotsPerEpoch; slot++ {
+		block, err := in.Chain.BlockAt(slot)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			continue
+		}
+		for _, d := range block.Deposits {
+			if index, ok := pubkeys[string(d.PublicKey)]; ok {
+				get(index).Deposits += int64(d.Amount)
+			}
+		}
+		indices := make([]uint64, 0)
+		for _, s := range block.ProposerSlashings {
+			indices = append(indices, s.ProposerIndex)
+		}
+		for _, s := range block.AttesterSlashings {
+			indices = append(indices, slashedIndices(s)...)
+		}
+		for _, index := range indices {
+			if slashed[index] {
+				continue
+			}
+			slashed[index] = true
+			get(index).Slashing -= int64(effective[index] / MinSlashingPenaltyQuotient)
+			// the proposer is the whistleblower
+			get(block.Proposer).Proposer += int64(effective[index] / WhistleblowerRewardQuotient)
+		}
+	}
+
+	out := &types.EpochRewards{
+		Epoch:      in.Epoch,
+		Validators: make([]types.ValidatorRewards, 0, len(in.NextBalances)),
+	}
+	for index, next := range in.NextBalances {
+		r := get(index)
+		balance, known := in.Balances[index]
+		rest := int64(next) - int64(balance) - r.Total()
+		switch {
+		case !known:
+			// the validator was deposited during the epoch
+			r.Deposits += rest
+		case rest >= 0:
+			r.Attestation = rest
+		default:
+			r.Penalties = rest
+		}
+		out.Validators = append(out.Validators, types.ValidatorRewards{Index: index, RewardsBreakdown: *r})
+		out.Total.Add(*r)
+	}
+	sort.Slice(out.Validators, func(i, j int) bool { return out.Validators[i].Index < out.Validators[j].Index })
+	return out, nil
+}
+
+// RewardsSource provides the data the rewards are computed from
+type RewardsSource interface {
+	PerformanceSource
+	GetEpochValidators(epoch uint64) ([]*types.Validator, error)
+	GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error)
+}
+
+// EpochRewards attributes the balance changes since the epoch till the next one,
+// which must be started
+func EpochRewards(source RewardsSource, epoch uint64) (*types.EpochRewards, error) {
+	in := &RewardsInput{Epoch: epoch, Chain: NewCanonicalChain(source)}
+	var err error
+	if in.Balances, err = source.GetBalancesForEpoch(int64(epoch)); err != nil {
+		return nil, err
+	}
+	if in.NextBalances, err = source.GetBalancesForEpoch(int64(epoch + 1)); err != nil {
+		return nil, err
+	}
+	if in.Validators, err = source.GetEpochValidators(epoch); err != nil {
+		return nil, err
+	}
+	if epoch > 0 {
+		assignments, err := source.GetEpochAssignments(epoch - 1)
+		if err != nil {
+			return nil, err
+		}
+		if in.Performance, err = ComputePerformance(assignments, in.Chain); err != nil {
+			return nil, err
+		}
+	}
+	return ComputeRewards(in)
+}
+
+// DayOfEpoch returns the day since genesis of the epoch
+func DayOfEpoch(epoch uint64) uint64 {
+	return epoch / types.EpochsPerDay
+}
+
+// RollupDays sums up the rewards of the epochs by their days since genesis, sorted by day
+func RollupDays(epochs []*types.EpochRewards) []*types.DayRewards {
+	days := make(map[uint64]*types.DayRewards)
+	validators := make(map[uint64]map[uint64]*types.RewardsBreakdown)
+	for _, e := range epochs {
+		day := DayOfEpoch(e.Epoch)
+		d, ok := days[day]
+		if !ok {
+			d = &types.DayRewards{Day: day, FirstEpoch: e.Epoch, LastEpoch: e.Epoch}
+			days[day] = d
+			validators[day] = make(map[uint64]*types.RewardsBreakdown)
+		}
+		if e.Epoch < d.FirstEpoch {
+			d.FirstEpoch = e.Epoch
+		}
+		if e.Epoch > d.LastEpoch {
+			d.LastEpoch = e.Epoch
+		}
+		d.Total.Add(e.Total)
+		for _, v := range e.Validators {
+			if _, ok := validators[day][v.Index]; !ok {
+				validators[day][v.Index] = &types.RewardsBreakdown{}
+			}
+			validators[day][v.Index].Add(v.RewardsBreakdown)
+		}
+	}
+	res := make([]*types.DayRewards, 0, len(days))
+	for day, d := range days {
+		d.Validators = make([]types.ValidatorRewards, 0, len(validators[day]))
+		for index, r := range validators[day] {
+			d.Validators = append(d.Validators, types.ValidatorRewards{Index: index, RewardsBreakdown: *r})
+		}
+		sort.Slice(d.Validators, func(i, j int) bool { return d.Validators[i].Index < d.Validators[j].Index })
+		res = append(res, d)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Day < res[j].Day })
+	return res
+}
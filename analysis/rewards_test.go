@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"beaconchain/types"
+	"reflect"
+	"testing"
+)
+
+func TestIntegerSquareRoot(t *testing.T) {
+	for n, expected := range map[uint64]uint64{0: 0, 1: 1, 3: 1, 4: 2, 99: 9, 100: 10, 6400000000000000: 80000000} {
+		if res := integerSquareRoot(n); res != expected {
+			t.Errorf("square root of %d is %d, expected %d", n, res, expected)
+		}
+	}
+}
+
+func TestEpochRewards(t *testing.T) {
+	client, node := rpctest.NewSource(t, fakenode.Config{
+		Validators: 200,
+		Epochs:     6,
+		Slashings:  map[uint64]uint64{100: 7},
+		Deposits:   []uint64{40},
+	})
+
+	rewards, err := EpochRewards(client, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the attestations of the epoch 2 are included by the next blocks, the slot 100 includes the slashing
+	proposers := map[uint64]bool{node.Chain.Proposer(100): true}
+	for slot := uint64(65); slot <= 96; slot++ {
+		proposers[node.Chain.Proposer(slot)] = true
+	}
+	total := types.RewardsBreakdown{}
+	for index := uint64(0); index < 201; index++ {
+		r, ok := rewards.Get(index)
+		if !ok {
+			t.Fatalf("validator %d has no rewards", index)
+		}
+		before, _ := node.Chain.Balance(index, 3)
+		after, _ := node.Chain.Balance(index, 4)
+		if r.Total() != int64(after)-int64(before) {
+			t.Errorf("validator %d: breakdown %+v does not sum up to the balance change", index, r.RewardsBreakdown)
+		}
+		if (r.Proposer > 0) != proposers[index] {
+			t.Errorf("validator %d: proposer reward %d", index, r.Proposer)
+		}
+		total.Add(r.RewardsBreakdown)
+	}
+	if total != rewards.Total {
+		t.Errorf("total %+v, expected %+v", rewards.Total, total)
+	}
+	slashed, _ := rewards.Get(7)
+	if slashed.Slashing != -31000000000/MinSlashingPenaltyQuotient {
+		t.Errorf("slashing penalty %d", slashed.Slashing)
+	}
+	whistleblower, _ := rewards.Get(node.Chain.Proposer(100))
+	if whistleblower.Proposer < 31000000000/WhistleblowerRewardQuotient {
+		t.Errorf("whistleblower reward %d", whistleblower.Proposer)
+	}
+
+	genesis, err := EpochRewards(client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposited, ok := genesis.Get(200)
+	balance, _ := node.Chain.Balance(200, 1)
+	if !ok || deposited.Deposits != int64(balance) || deposited.Attestation != 0 {
+		t.Errorf("deposit of the new validator %+v", deposited)
+	}
+}
+
+func TestRollupDays(t *testing.T) {
+	epoch := func(epoch uint64, rewards ...types.RewardsBreakdown) *types.EpochRewards {
+		e := &types.EpochRewards{Epoch: epoch}
+		for index, r := range rewards {
+			e.Validators = append(e.Validators, types.ValidatorRewards{Index: uint64(index), RewardsBreakdown: r})
+			e.Total.Add(r)
+		}
+		return e
+	}
+	days := RollupDays([]*types.EpochRewards{
+		epoch(226, types.RewardsBreakdown{Attestation: 3}, types.RewardsBreakdown{Penalties: -2}),
+		epoch(224, types.RewardsBreakdown{Attestation: 5}),
+		epoch(225, types.RewardsBreakdown{Attestation: 1, Proposer: 7}),
+	})
+	expected := []*types.DayRewards{
+		{
+			Day: 0, FirstEpoch: 224, LastEpoch: 224,
+			Validators: []types.ValidatorRewards{{Index: 0, RewardsBreakdown: types.RewardsBreakdown{Attestation: 5}}},
+			Total:      types.RewardsBreakdown{Attestation: 5},
+		},
+		{
+			Day: 1, FirstEpoch: 225, LastEpoch: 226,
+			Validators: []types.ValidatorRewards{
+				{Index: 0, RewardsBreakdown: types.RewardsBreakdown{Attestation: 4, Proposer: 7}},
+				{Index: 1, RewardsBreakdown: types.RewardsBreakdown{Penalties: -2}},
+			},
+			Total: types.RewardsBreakdown{Attestation: 4, Proposer: 7, Penalties: -2},
+		},
+	}
+	if !reflect.DeepEqual(days, expected) {
+		t.Errorf("unexpected rollups %+v", days)
+	}
+}
@@ -0,0 +1,197 @@
+package analysis
+
+import (
+	"beaconchain/types"
+	"fmt"
+	"sort"
+)
+
+// BlockSlashings returns the validators slashed by the evidence of the block,
+// the proposer of the block is the whistleblower
+func BlockSlashings(block *types.Block) []types.SlashingEvent {
+	res := make([]types.SlashingEvent, 0)
+	for _, s := range block.ProposerSlashings {
+		res = append(res, types.SlashingEvent{
+			Slot:             block.Slot,
+			BlockRoot:        block.BlockRoot,
+			Validator:        s.ProposerIndex,
+			Kind:             types.SlashingDoubleProposal,
+			Whistleblower:    block.Proposer,
+			ProposerSlashing: s,
+		})
+	}
+	for _, s := range block.AttesterSlashings {
+		kind := types.SlashingDoubleVote
+		if s.Attestation1 != nil && s.Attestation2 != nil {
+			kind, _ = types.VoteKind(s.Attestation1.Data, s.Attestation2.Data)
+		}
+		for _, index := range slashedIndices(s) {
+			res = append(res, types.SlashingEvent{
+				Slot:             block.Slot,
+				BlockRoot:        block.BlockRoot,
+				Validator:        index,
+				Kind:             kind,
+				Whistleblower:    block.Proposer,
+				AttesterSlashing: s,
+			})
+		}
+	}
+	return res
+}
+
+// SlashingEvents returns the slashings of the canonical blocks of the epochs from-to inclusive,
+// the validator is reported once, by the first block slashing it
+func SlashingEvents(source BlockSource, from, to uint64) ([]types.SlashingEvent, error) {
+	chain := NewCanonicalChain(source)
+	slashed := make(map[uint64]bool)
+	res := make([]types.SlashingEvent, 0)
+	for slot := from * types.SlotsPerEpoch; slot < (to+1)*types.SlotsPerEpoch; slot++ {
+		block, err := chain.BlockAt(slot)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			continue
+		}
+		for _, e := range BlockSlashings(block) {
+			if !slashed[e.Validator] {
+				slashed[e.Validator] = true
+				res = append(res, e)
+			}
+		}
+	}
+	return res, nil
+}
+
+// Detector finds the double and surround votes of the validators in the included attestations,
+// the votes are remembered by the validator and the target epoch
+type Detector struct {
+	votes map[uint64]map[uint64][]types.SlashableVote
+	// the latest target of the validator bounds the search of the surrounding votes
+	maxTarget map[uint64]uint64
+	offences  []types.SlashableOffence
+}
+
+// NewDetector returns the detector without the votes
+func NewDetector() *Detector {
+	return &Detector{
+		votes:     make(map[uint64]map[uint64][]types.SlashableVote),
+		maxTarget: make(map[uint64]uint64),
+		offences:  make([]types.SlashableOffence, 0),
+	}
+}
+
+// AddBlock checks the votes of the attestations of the block, canonical or not,
+// against the votes of the same validators seen before
+func (d *Detector) AddBlock(block *types.Block) {
+	for _, a := range block.Attestations {
+		if a.Data == nil || a.Data.Source == nil || a.Data.Target == nil {
+			continue
+		}
+		vote := types.SlashableVote{Data: a.Data, InclusionSlot: block.Slot, BlockRoot: block.BlockRoot}
+		for _, index := range a.Attesters {
+			d.addVote(index, vote)
+		}
+	}
+}
+
+func (d *Detector) addVote(index uint64, vote types.SlashableVote) {
+	targets, ok := d.votes[index]
+	if !ok {
+		targets = make(map[uint64][]types.SlashableVote)
+		d.votes[index] = targets
+	}
+	source, target := vote.Data.Source.Epoch, vote.Data.Target.Epoch
+	for _, v := range targets[target] {
+		if v.Data.Equal(vote.Data) {
+			// the same vote is included again
+			return
+		}
+	}
+
+	// the conflicting votes have the same target or the target after the source of the vote,
+	// the votes of the earlier targets can neither surround it nor be surrounded
+	first := source + 1
+	if target < first {
+		first = target
+	}
+	last := d.maxTarget[index]
+	if target > last {
+		last = target
+	}
+	for t := first; t <= last; t++ {
+		for _, v := range targets[t] {
+			if kind, slashable := types.VoteKind(v.Data, vote.Data); slashable {
+				d.offences = append(d.offences, types.SlashableOffence{Validator: index, Kind: kind, Vote1: v, Vote2: vote})
+			}
+		}
+	}
+	targets[target] = append(targets[target], vote)
+	if target > d.maxTarget[index] {
+		d.maxTarget[index] = target
+	}
+}
+
+// Offences returns the detected offences, sorted by the validator and the slot of the second vote
+func (d *Detector) Offences() []types.SlashableOffence {
+	res := append([]types.SlashableOffence{}, d.offences...)
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Validator != res[j].Validator {
+			return res[i].Validator < res[j].Validator
+		}
+		return res[i].Vote2.InclusionSlot < res[j].Vote2.InclusionSlot
+	})
+	return res
+}
+
+// DetectOffences scans the attestations of all blocks of the epochs from-to inclusive, in the order of the slots
+func DetectOffences(source BlockSource, from, to uint64) ([]types.SlashableOffence, error) {
+	if to < from {
+		return nil, fmt.Errorf("empty range of epochs %d-%d", from, to)
+	}
+	d := NewDetector()
+	for epoch := from; epoch <= to; epoch++ {
+		blocks, err := source.GetEpochBlocks(epoch)
+		if err != nil {
+			return nil, err
+		}
+		sorted := append([]*types.Block{}, blocks...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Slot < sorted[j].Slot })
+		for _, block := range sorted {
+			d.AddBlock(block)
+		}
+	}
+	return d.Offences(), nil
+}
+
+// CrossCheck compares the detected offences with the slashings of the network by the validator,
+// the proposer slashings are not detected from the attestations and are skipped
+func CrossCheck(offences []types.SlashableOffence, events []types.SlashingEvent) *types.SlashingCrossCheck {
+	slashed := make(map[uint64]bool, len(events))
+	for _, e := range events {
+		slashed[e.Validator] = true
+	}
+	detected := make(map[uint64]bool, len(offences))
+	res := &types.SlashingCrossCheck{
+		Slashed:    make([]uint64, 0),
+		Unslashed:  make([]types.SlashableOffence, 0),
+		Undetected: make([]types.SlashingEvent, 0),
+	}
+	for _, o := range offences {
+		if slashed[o.Validator] {
+			if !detected[o.Validator] {
+				res.Slashed = append(res.Slashed, o.Validator)
+			}
+		} else {
+			res.Unslashed = append(res.Unslashed, o)
+		}
+		detected[o.Validator] = true
+	}
+	sort.Slice(res.Slashed, func(i, j int) bool { return res.Slashed[i] < res.Slashed[j] })
+	for _, e := range events {
+		if e.Kind != types.SlashingDoubleProposal && !detected[e.Validator] {
+			res.Undetected = append(res.Undetected, e)
+		}
+	}
+	return res
+}
@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"beaconchain/types"
+	"testing"
+)
+
+func vote(slot, source, target uint64, root byte) *types.AttestationData {
+	return &types.AttestationData{
+		Slot:            slot,
+		BeaconBlockRoot: []byte{root},
+		Source:          &types.Checkpoint{Epoch: source, Root: []byte{byte(source)}},
+		Target:          &types.Checkpoint{Epoch: target, Root: []byte{byte(target)}},
+	}
+}
+
+func TestSlashingEvents(t *testing.T) {
+	client, node := rpctest.NewSource(t, fakenode.Config{
+		Validators: 100,
+		Epochs:     5,
+		Slashings:  map[uint64]uint64{100: 7},
+	})
+	// the validators 3 and 4 vote twice, the validator 7 is already slashed
+	slashing := &types.AttesterSlashing{
+		Attestation1: &types.IndexedAttestation{Data: vote(64, 1, 2, 0xaa), AttestingIndices: []uint64{3, 4, 5, 7}},
+		Attestation2: &types.IndexedAttestation{Data: vote(65, 1, 2, 0xbb), AttestingIndices: []uint64{2, 3, 4, 7}},
+	}
+	source := editedBlocks{client, func(block *types.Block) {
+		if block.Slot == 101 && block.Canonical {
+			block.AttesterSlashings = []*types.AttesterSlashing{slashing}
+		}
+	}}
+
+	events, err := SlashingEvents(source, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	proposal := events[0]
+	if proposal.Validator != 7 || proposal.Kind != types.SlashingDoubleProposal || proposal.Slot != 100 ||
+		proposal.Whistleblower != node.Chain.Proposer(100) || proposal.ProposerSlashing == nil {
+		t.Errorf("unexpected proposer slashing %+v", proposal)
+	}
+	for i, index := range []uint64{3, 4} {
+		e := events[i+1]
+		if e.Validator != index || e.Kind != types.SlashingDoubleVote || e.Slot != 101 || e.Whistleblower != node.Chain.Proposer(101) || e.AttesterSlashing != slashing {
+			t.Errorf("unexpected attester slashing %+v", e)
+		}
+	}
+}
+
+func TestDetector(t *testing.T) {
+	block := func(slot uint64, attestations ...*types.Attestation) *types.Block {
+		return &types.Block{Slot: slot, BlockRoot: []byte{byte(slot)}, Attestations: attestations}
+	}
+	attest := func(data *types.AttestationData, attesters ...uint64) *types.Attestation {
+		return &types.Attestation{Data: data, Attesters: attesters}
+	}
+	d := NewDetector()
+	d.AddBlock(block(65, attest(vote(64, 1, 2, 0xaa), 1, 2, 3), attest(vote(40, 0, 4, 0xcc), 4)))
+	// the same vote is included again
+	d.AddBlock(block(66, attest(vote(64, 1, 2, 0xaa), 1, 2), attest(vote(40, 0, 4, 0xcc), 4)))
+	// the validator 2 votes for another head of the same target
+	d.AddBlock(block(67, attest(vote(65, 1, 2, 0xbb), 2)))
+	// the vote of the validator 3 surrounds its earlier vote, the one of the validator 4 is surrounded
+	d.AddBlock(block(130, attest(vote(129, 0, 4, 0xdd), 3), attest(vote(100, 2, 3, 0xdd), 4)))
+	// the validator 1 votes for the next epochs
+	d.AddBlock(block(131, attest(vote(129, 2, 4, 0xdd), 1)))
+
+	offences := d.Offences()
+	if len(offences) != 3 {
+		t.Fatalf("unexpected offences %+v", offences)
+	}
+	if o := offences[0]; o.Validator != 2 || o.Kind != types.SlashingDoubleVote || o.Vote1.InclusionSlot != 65 || o.Vote2.InclusionSlot != 67 {
+		t.Errorf("unexpected double vote %+v", o)
+	}
+	if o := offences[1]; o.Validator != 3 || o.Kind != types.SlashingSurroundVote || o.Vote2.Data.Source.Epoch != 0 {
+		t.Errorf("unexpected surrounding vote %+v", o)
+	}
+	if o := offences[2]; o.Validator != 4 || o.Kind != types.SlashingSurroundVote || o.Vote1.InclusionSlot != 65 || o.Vote2.Data.Source.Epoch != 2 {
+		t.Errorf("unexpected surrounded vote %+v", o)
+	}
+}
+
+func TestDetectOffencesAfterReorg(t *testing.T) {
+	client, node := rpctest.NewSource(t, fakenode.Config{Validators: 100, Epochs: 4})
+
+	offences, err := DetectOffences(client, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offences) != 0 {
+		t.Fatalf("offences of the honest chain %+v", offences)
+	}
+
+	// the attesters of the slots 70-94 voted for both forks
+	node.Reorg(70)
+	for epoch := uint64(2); epoch <= 3; epoch++ {
+		if err := client.InvalidateEpoch(epoch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := make(map[uint64]bool)
+	for slot := uint64(70); slot <= 94; slot++ {
+		for _, committee := range node.Chain.Committees(2)[slot-64] {
+			for _, index := range committee {
+				expected[index] = true
+			}
+		}
+	}
+	offences, err = DetectOffences(client, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offences) != len(expected) {
+		t.Fatalf("%d offences, expected %d", len(offences), len(expected))
+	}
+	for _, o := range offences {
+		if !expected[o.Validator] || o.Kind != types.SlashingDoubleVote || o.Vote1.Data.Target.Epoch != 2 {
+			t.Errorf("unexpected offence %+v", o)
+		}
+	}
+
+	honest := uint64(0)
+	for expected[honest] {
+		honest++
+	}
+	check := CrossCheck(offences, []types.SlashingEvent{
+		{Validator: offences[0].Validator, Kind: types.SlashingDoubleVote},
+		{Validator: honest, Kind: types.SlashingSurroundVote},
+		{Validator: 1000, Kind: types.SlashingDoubleProposal},
+	})
+	if len(check.Slashed) != 1 || len(check.Unslashed) != len(offences)-1 || len(check.Undetected) != 1 || check.Undetected[0].Validator != honest {
+		t.Errorf("unexpected cross-check %+v", check)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"beaconchain/analysis"
+	"beaconchain/rpc"
+	"beaconchain/types"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.New().WithField("module", "blocktree")
+
+var api = flag.String("api", "cache", "API of the host: prysm (v1alpha1 gRPC), rest (standard /eth/v1) or cache (offline, host is ignored)")
+var host = flag.String("host", "localhost:4000", "host to connect to, a gRPC host with ?duties=<standard API URL> gets the assignments from the beacon committees, with ?mixes=<standard API URL> computes them from the RANDAO mixes")
+var cacheDir = flag.String("cache", "/cache", "folder of the cache files")
+var from = flag.Int64("from", -1, "first slot of the range, the range before the last slot by default")
+var to = flag.Int64("to", -1, "last slot of the range, the head slot by default")
+var slots = flag.Uint64("slots", 64, "number of slots in the range, when the first slot is not set")
+var format = flag.String("format", "dot", "output format: dot (Graphviz) or json")
+
+// checkpoint is the block of the chain head, which is justified or finalized
+type checkpoint struct {
+	Name  string `json:"name"`
+	Epoch uint64 `json:"epoch"`
+	Root  string `json:"root"`
+}
+
+// checkpoints lists the checkpoints of the chain head, the finalized one first
+func checkpoints(head *types.ChainHead) []checkpoint {
+	return []checkpoint{
+		{Name: "finalized", Epoch: head.FinalizedEpoch, Root: hexRoot(head.FinalizedBlockRoot)},
+		{Name: "justified", Epoch: head.JustifiedEpoch, Root: hexRoot(head.JustifiedBlockRoot)},
+		{Name: "previous_justified", Epoch: head.PreviousJustifiedEpoch, Root: hexRoot(head.PreviousJustifiedBlockRoot)},
+	}
+}
+
+// checkpointOf returns the names of the checkpoints of the root, the first one is the most final
+func checkpointOf(points []checkpoint, root string) []string {
+	res := make([]string, 0)
+	for _, c := range points {
+		if c.Root == root {
+			res = append(res, c.Name)
+		}
+	}
+	return res
+}
+
+func hexRoot(root []byte) string {
+	return fmt.Sprintf("0x%x", root)
+}
+
+// jsonNode is the block of the tree, linked to its parent by the root
+type jsonNode struct {
+	Slot         uint64   `json:"slot"`
+	Root         string   `json:"root"`
+	ParentRoot   string   `json:"parent_root"`
+	Proposer     uint64   `json:"proposer"`
+	Canonical    bool     `json:"canonical"`
+	Attestations int      `json:"attestations"`
+	Checkpoints  []string `json:"checkpoints,omitempty"`
+}
+
+// writeJSON writes the blocks of the tree as the list of the nodes, parents before children
+func writeJSON(w io.Writer, tree *types.ForkTree, head *types.ChainHead) error {
+	points := checkpoints(head)
+	nodes := make([]jsonNode, 0)
+	tree.Walk(func(node *types.ForkNode, _ int) {
+		root := hexRoot(node.Root)
+		n := jsonNode{
+			Slot:         node.Slot,
+			Root:         root,
+			ParentRoot:   hexRoot(node.ParentRoot),
+			Proposer:     node.Proposer,
+			Canonical:    node.Canonical,
+			Attestations: node.Attestations,
+		}
+		if names := checkpointOf(points, root); len(names) > 0 {
+			n.Checkpoints = names
+		}
+		nodes = append(nodes, n)
+	})
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"from_slot":   tree.FromSlot,
+		"to_slot":     tree.ToSlot,
+		"head_root":   hexRoot(head.HeadBlockRoot),
+		"checkpoints": points,
+		"nodes":       nodes,
+	})
+}
+
+// writeDOT draws the tree in the Graphviz format: orphaned blocks are grey and dashed,
+// the finalized checkpoint is green, the justified ones are orange
+func writeDOT(w io.Writer, tree *types.ForkTree, head *types.ChainHead) error {
+	points := checkpoints(head)
+	var bb bytes.Buffer
+	fmt.Fprintf(&bb, "digraph blocks {\n")
+	fmt.Fprintf(&bb, "  label=\"slots %d-%d\";\n", tree.FromSlot, tree.ToSlot)
+	fmt.Fprintf(&bb, "  rankdir=LR;\n")
+	fmt.Fprintf(&bb, "  node [shape=box, style=filled, fillcolor=white];\n")
+	tree.Walk(func(node *types.ForkNode, _ int) {
+		root := hexRoot(node.Root)
+		label := fmt.Sprintf("%d\\n%.10s\\nproposer %d\\n%d attestations", node.Slot, root, node.Proposer, node.Attestations)
+		attrs := ""
+		if !node.Canonical {
+			attrs += ", style=\"filled,dashed\", fillcolor=lightgrey"
+		}
+		if names := checkpointOf(points, root); len(names) > 0 {
+			color := "orange"
+			if names[0] == "finalized" {
+				color = "darkgreen"
+			}
+			for _, name := range names {
+				label += "\\n" + name
+			}
+			attrs += fmt.Sprintf(", color=%s, penwidth=3", color)
+		}
+		if root == hexRoot(head.HeadBlockRoot) {
+			label += "\\nhead"
+		}
+		fmt.Fprintf(&bb, "  %q [label=\"%s\"%s];\n", root, label, attrs)
+	})
+	tree.Walk(func(node *types.ForkNode, _ int) {
+		for _, child := range node.Children {
+			style := ""
+			if !child.Canonical {
+				style = " [style=dashed]"
+			}
+			fmt.Fprintf(&bb, "  %q -> %q%s;\n", hexRoot(node.Root), hexRoot(child.Root), style)
+		}
+	})
+	fmt.Fprintf(&bb, "}\n")
+	_, err := w.Write(bb.Bytes())
+	return err
+}
+
+// run writes the block tree of the range of slots, the last slot defaults to the head slot
+func run(source rpc.BeaconSource, first, last int64, slots uint64, format string, w io.Writer) error {
+	head, err := source.GetChainHead()
+	if err != nil {
+		return err
+	}
+	if last < 0 {
+		last = int64(head.HeadSlot)
+	}
+	if first < 0 {
+		first = last - int64(slots) + 1
+		if first < 0 {
+			first = 0
+		}
+	}
+	if first > last {
+		return fmt.Errorf("empty range of slots %d-%d", first, last)
+	}
+	tree, err := analysis.BlockTree(source, uint64(first), uint64(last))
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "dot":
+		return writeDOT(w, tree, head)
+	case "json":
+		return writeJSON(w, tree, head)
+	}
+	return fmt.Errorf("unknown format %q, expected dot or json", format)
+}
+
+func main() {
+	flag.Parse()
+	rpc.SetCacheDir(*cacheDir)
+
+	source, err := rpc.NewSource(*api, *host)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer source.Close()
+
+	if err := run(source, *from, *to, *slots, *format, os.Stdout); err != nil {
+		logger.Fatal(err)
+	}
+}
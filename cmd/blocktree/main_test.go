@@ -0,0 +1,95 @@
+package main
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newSource(t *testing.T) (rpc.BeaconSource, *fakenode.Node) {
+	return rpctest.NewSource(t, fakenode.Config{Validators: 100, Epochs: 4, OrphanedSlots: []uint64{70}})
+}
+
+func TestRunWritesJSON(t *testing.T) {
+	source, node := newSource(t)
+
+	var out bytes.Buffer
+	if err := run(source, 32, -1, 0, "json", &out); err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		FromSlot uint64     `json:"from_slot"`
+		ToSlot   uint64     `json:"to_slot"`
+		Nodes    []jsonNode `json:"nodes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	// the head is at the slot 127, the finalized epoch is 1 and the justified one is 2
+	if body.FromSlot != 32 || body.ToSlot != 127 || len(body.Nodes) != 97 {
+		t.Fatalf("slots %d-%d with %d blocks", body.FromSlot, body.ToSlot, len(body.Nodes))
+	}
+	orphans := 0
+	for _, n := range body.Nodes {
+		switch n.Root {
+		case hexRoot(node.Chain.CanonicalRoot(32)):
+			if !reflect.DeepEqual(n.Checkpoints, []string{"finalized", "previous_justified"}) {
+				t.Errorf("checkpoints of the slot 32: %v", n.Checkpoints)
+			}
+		case hexRoot(node.Chain.CanonicalRoot(64)):
+			if !reflect.DeepEqual(n.Checkpoints, []string{"justified"}) {
+				t.Errorf("checkpoints of the slot 64: %v", n.Checkpoints)
+			}
+		default:
+			if len(n.Checkpoints) != 0 {
+				t.Errorf("slot %d: unexpected checkpoints %v", n.Slot, n.Checkpoints)
+			}
+		}
+		if !n.Canonical {
+			orphans++
+			if n.Slot != 70 || n.ParentRoot != hexRoot(node.Chain.CanonicalRoot(69)) {
+				t.Errorf("unexpected orphan %+v", n)
+			}
+		}
+		if n.Canonical && n.Slot > 32 && n.Attestations != 1 {
+			t.Errorf("slot %d: %d attestations", n.Slot, n.Attestations)
+		}
+	}
+	if orphans != 1 {
+		t.Errorf("%d orphans", orphans)
+	}
+}
+
+func TestRunWritesDOT(t *testing.T) {
+	source, node := newSource(t)
+
+	var out bytes.Buffer
+	if err := run(source, -1, 95, 32, "dot", &out); err != nil {
+		t.Fatal(err)
+	}
+	dot := out.String()
+	if !strings.HasPrefix(dot, "digraph blocks {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("unexpected graph\n%s", dot)
+	}
+	if edges := strings.Count(dot, " -> "); edges != 32 {
+		t.Errorf("%d edges, expected 32", edges)
+	}
+	justified := fmt.Sprintf("%q [label=", hexRoot(node.Chain.CanonicalRoot(64)))
+	for _, expected := range []string{justified, "justified\", color=orange, penwidth=3];", "style=\"filled,dashed\", fillcolor=lightgrey", " [style=dashed];"} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("graph does not contain %s", expected)
+		}
+	}
+	if strings.Contains(dot, "darkgreen") {
+		t.Error("finalized checkpoint is out of the range")
+	}
+	if err := run(source, 96, 95, 0, "dot", &out); err == nil {
+		t.Error("empty range is written")
+	}
+}
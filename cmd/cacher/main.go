@@ -1,7 +1,10 @@
 package main
 
 import (
+	"beaconchain/analysis"
 	"beaconchain/rpc"
+	"beaconchain/rpc/recording"
+	"beaconchain/types"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,17 +14,18 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 var logger = logrus.New().WithField("module", "cacher")
 
 type clients struct {
 	index int
-	list  []*rpc.PrysmClient
+	list  []rpc.BeaconSource
 	hosts []string
 }
 
-func (s *clients) Get() *rpc.PrysmClient {
+func (s *clients) Get() rpc.BeaconSource {
 	return s.list[s.index]
 }
 
@@ -29,21 +33,21 @@ func (s *clients) Len() int {
 	return len(s.hosts)
 }
 
-func (s *clients) Next() *rpc.PrysmClient {
+func (s *clients) Next() rpc.BeaconSource {
 	s.index = (s.index + 1) % len(s.list)
 	logger.Printf("switched to %v\n", s.hosts[s.index])
 	return s.Get()
 }
 
-func NewClients(hosts []string) (*clients, error) {
+func NewClients(api string, hosts []string, opts ...grpc.DialOption) (*clients, error) {
 	s := &clients{
 		index: 0,
-		list:  make([]*rpc.PrysmClient, 0),
+		list:  make([]rpc.BeaconSource, 0),
 		hosts: hosts,
 	}
 	for _, host := range hosts {
-		logger.Printf("connecting to RPC of %v", host)
-		client, err := rpc.NewPrysmClient(host)
+		logger.Printf("connecting to %v API of %v", api, host)
+		client, err := rpc.NewSource(api, host, opts...)
 		if err != nil {
 			logger.Printf("error connecting to %v: %v", host, err)
 		} else {
@@ -51,12 +55,13 @@ func NewClients(hosts []string) (*clients, error) {
 		}
 	}
 	if len(s.list) == 0 {
-		return nil, errors.New("no Prysm clients to connect")
+		return nil, errors.New("no beacon node clients to connect")
 	}
 	return s, nil
 }
 
-var hosts = flag.String("hosts", "localhost:4000", "comma-separated list of hosts to connect to")
+var hosts = flag.String("hosts", "localhost:4000", "comma-separated list of hosts to connect to, a gRPC host with ?duties=<standard API URL> gets the assignments from the beacon committees, with ?mixes=<standard API URL> computes them from the RANDAO mixes")
+var api = flag.String("api", "prysm", "API of the hosts: prysm (v1alpha1 gRPC), rest (standard /eth/v1) or cache (offline, hosts are ignored)")
 var gethead = flag.Bool("get-head", false, "return head of")
 var head = flag.Int("head", 0, "block to start reading")
 var offset = flag.Int("offset", 0, "in case of head, offset from the head")
@@ -64,10 +69,21 @@ var limit = flag.Int("limit", 1000, "max number of epochs to look at")
 var timeout = flag.Int("timeout", 0, "time period, in minutes (watching head only). If takes less, process will wait for this period after job is done")
 var debug = flag.Bool("debug", false, "do some debugging instead of the job")
 var inc = flag.Bool("inc", false, "do through epochs incrementally")
+var cacheDir = flag.String("cache", "/cache", "folder of the cache files")
+var parallelism = flag.Int("parallel", 4, "max number of concurrent requests to each node")
+var record = flag.String("record", "", "folder to record responses of the gRPC nodes into, for replaying")
+var lookback = flag.String("lookback", "1d,7d,31d,activation", "comma-separated windows of the balance history of the validators, like 1h, 1d, 30d, 365d or activation, remembered in activation.balances.gz once finalized")
 
 var cacheBalances = flag.Bool("balances", true, "cache balances")
 var cacheValidators = flag.Bool("validators", true, "cache validator lists")
 var cacheAssignments = flag.Bool("assignments", true, "cache assignmenets")
+var cacheBlocks = flag.Bool("blocks", false, "cache blocks")
+var cacheParticipation = flag.Bool("participation", true, "cache validator participation of the finished epochs")
+var cacheHeads = flag.Bool("heads", true, "record the chain head, observed when the epoch is cached")
+var cachePerformance = flag.Bool("performance", false, "save attestation performance of the validators, once the next epoch is finished")
+var cacheProposals = flag.Bool("proposals", false, "save outcomes of the proposer duties of the started epochs, once every slot is reached")
+var cacheRewards = flag.Bool("rewards", false, "save rewards breakdown of the validators once the next epoch is started, and the rollups of the complete days")
+var detectReorgs = flag.Bool("reorgs", true, "invalidate the cached epochs since the finalized one, which cached blocks are replaced on the node (prysm API only)")
 
 func main() {
 	err := godotenv.Load()
@@ -75,9 +91,10 @@ func main() {
 		logger.Fatal("Error loading .env file")
 	}
 	flag.Parse()
+	rpc.SetCacheDir(*cacheDir)
 
 	if *gethead {
-		client, err := rpc.NewPrysmClient(*hosts)
+		client, err := rpc.NewSource(*api, *hosts)
 		if err != nil {
 			logger.Fatal(err)
 		}
@@ -88,10 +105,28 @@ func main() {
 		fmt.Print(head.HeadEpoch)
 		os.Exit(0)
 	}
-	clients, err := NewClients(strings.Split(*hosts, ","))
+	opts := make([]grpc.DialOption, 0)
+	if *record != "" {
+		recorder, err := recording.NewRecorder(*record)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		opts = append(opts, grpc.WithUnaryInterceptor(recorder))
+	}
+	windows, err := rpc.ParseLookbacks(*lookback)
 	if err != nil {
 		logger.Fatal(err)
 	}
+	clients, err := NewClients(*api, strings.Split(*hosts, ","), opts...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	for _, client := range clients.list {
+		client.SetParallelism(*parallelism)
+		client.SetLookbacks(windows)
+		// cached data of the epochs, finalized since then, is fetched again
+		client.SetAcceptProvisional(false)
+	}
 
 	if *debug {
 		// epoch := uint64(49050)
@@ -112,12 +147,233 @@ func main() {
 		os.Exit(0)
 	}
 
+	since := time.Now()
+	if *detectReorgs {
+		if err := invalidateReorgs(clients); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	if err := run(clients, since); err != nil {
+		logger.Fatal(err)
+	}
+	if err := seal(clients); err != nil {
+		logger.Fatal(err)
+	}
+
+	if timeout != nil && *timeout > 0 {
+		dur := time.Now().Sub(since)
+		maxDuration := time.Duration(*timeout) * time.Minute
+		if dur < maxDuration {
+			logger.Printf("sleeping for %v", maxDuration-dur)
+			time.Sleep(maxDuration - dur)
+		}
+	}
+}
+
+// cacheEpoch caches the datasets of the epoch, selected by the flags,
+// returns the name of the failed dataset with the error
+func cacheEpoch(client rpc.BeaconSource, epoch uint64) (string, error) {
+	head, err := client.GetChainHead()
+	if err != nil {
+		return "head", err
+	}
+	if *cacheHeads && !rpc.HasChainHead(epoch) {
+		snapshot := &types.ChainHeadSnapshot{Epoch: epoch, Timestamp: time.Now().Unix(), ChainHead: *head}
+		if err := rpc.SaveChainHead(epoch, snapshot); err != nil {
+			return "head", err
+		}
+	}
+	if *cacheBalances {
+		if _, err := client.GetBalancesForEpoch(int64(epoch)); err != nil {
+			return "balances", err
+		}
+	}
+	if *cacheValidators {
+		if _, err := client.GetEpochValidators(epoch); err != nil {
+			return "validators", err
+		}
+	}
+	if *cacheAssignments {
+		if _, err := client.GetEpochAssignments(epoch); err != nil {
+			return "assignment", err
+		}
+	}
+	if *cacheBlocks {
+		if _, err := client.GetEpochBlocks(epoch); err != nil {
+			return "blocks", err
+		}
+	}
+	// participation of the head epoch is not known yet, it is cached when the epoch is sealed
+	if *cacheParticipation && epoch < head.HeadEpoch {
+		_, err := client.GetValidatorParticipation(epoch)
+		if err != nil && !errors.Is(err, rpc.ErrNotSupported) {
+			return "participation", err
+		}
+	}
+	// attestations of the epoch are included till the end of the next epoch
+	fn := rpc.FnPerformance(epoch)
+	if *cachePerformance && epoch+1 < head.HeadEpoch && (!rpc.HasPerformance(epoch) || rpc.IsProvisional(fn)) {
+		performance, err := analysis.EpochPerformance(client, epoch)
+		if err != nil {
+			return "performance", err
+		}
+		if err := rpc.SavePerformance(epoch, performance); err != nil {
+			return "performance", err
+		}
+		if err := rpc.MarkFinality(fn, epoch+1, head.FinalizedEpoch); err != nil {
+			return "performance", err
+		}
+	}
+	// blocks of the finished epoch can still be orphaned, until it is finalized
+	fn = rpc.FnProposals(epoch)
+	if *cacheProposals && epoch < head.HeadEpoch && (!rpc.HasProposals(epoch) || rpc.IsProvisional(fn)) {
+		proposals, err := rpc.EpochProposals(client, epoch)
+		if err != nil {
+			return "proposals", err
+		}
+		if proposals.Finished() {
+			if err := rpc.SaveProposals(epoch, proposals); err != nil {
+				return "proposals", err
+			}
+			if err := rpc.MarkFinality(fn, epoch, head.FinalizedEpoch); err != nil {
+				return "proposals", err
+			}
+		}
+	}
+	// balance changes of the epoch are known once the next epoch is started
+	if *cacheRewards && epoch < head.HeadEpoch {
+		if err := saveRewards(client, epoch, head); err != nil {
+			return "rewards", err
+		}
+	}
+	return "", nil
+}
+
+// saveRewards caches the rewards of the epoch, unless they are sealed,
+// and the rollup of its day once the rewards of every epoch of the day are cached
+func saveRewards(client rpc.BeaconSource, epoch uint64, head *types.ChainHead) error {
+	fn := rpc.FnRewards(epoch)
+	if !rpc.HasRewards(epoch) || rpc.IsProvisional(fn) {
+		rewards, err := analysis.EpochRewards(client, epoch)
+		if err != nil {
+			return err
+		}
+		if err := rpc.SaveRewards(epoch, rewards); err != nil {
+			return err
+		}
+		if err := rpc.MarkFinality(fn, epoch+1, head.FinalizedEpoch); err != nil {
+			return err
+		}
+	}
+
+	day := analysis.DayOfEpoch(epoch)
+	fnDay := rpc.FnDayRewards(day)
+	if rpc.HasDayRewards(day) && !rpc.IsProvisional(fnDay) {
+		return nil
+	}
+	epochs := make([]*types.EpochRewards, 0, types.EpochsPerDay)
+	for e := day * types.EpochsPerDay; e < (day+1)*types.EpochsPerDay; e++ {
+		if !rpc.HasRewards(e) {
+			// the day is not complete yet
+			return nil
+		}
+		rewards, err := rpc.LoadRewards(e)
+		if err != nil {
+			return err
+		}
+		epochs = append(epochs, rewards)
+	}
+	if err := rpc.SaveDayRewards(day, analysis.RollupDays(epochs)[0]); err != nil {
+		return err
+	}
+	return rpc.MarkFinality(fnDay, (day+1)*types.EpochsPerDay, head.FinalizedEpoch)
+}
+
+// failure is the way to handle the error of caching the epoch
+type failure int
+
+const (
+	// retry the epoch on the next node, skip it once every node failed
+	failureRetry failure = iota
+	// skip the epoch, no node can provide it
+	failureSkip
+	// retry the epoch on the next node, abort the run once every node is unavailable
+	failureUnavailable
+)
+
+// classify decides how to handle the error of caching the epoch
+func classify(err error) failure {
+	switch {
+	case errors.Is(err, rpc.ErrNotCached), errors.Is(err, rpc.ErrNotFound):
+		return failureSkip
+	case errors.Is(err, rpc.ErrNodeUnavailable):
+		return failureUnavailable
+	}
+	// partial pages and the rejected requests could succeed on another node
+	return failureRetry
+}
+
+// seal caches again the epochs, which were not finalized when they were cached
+func seal(clients *clients) error {
+	head, err := clients.Get().GetChainHead()
+	if err != nil {
+		return err
+	}
+	epochs, err := rpc.ProvisionalEpochs()
+	if err != nil {
+		return err
+	}
+	for _, epoch := range epochs {
+		if epoch > head.FinalizedEpoch {
+			break
+		}
+		start := time.Now()
+		if dataset, err := cacheEpoch(clients.Get(), epoch); err != nil {
+			// the epoch stays provisional, it is sealed by the next run
+			logger.Printf("[%v] sealing epoch %d error: %v\n", dataset, epoch, err)
+			clients.Next()
+			continue
+		}
+		logger.Printf("sealed epoch %d, took %v", epoch, time.Since(start))
+	}
+	return nil
+}
+
+// invalidateReorgs compares the cached blocks of the epochs, which are not finalized, with the node
+// and invalidates the epochs, which canonical blocks are replaced, so they are cached again
+func invalidateReorgs(clients *clients) error {
+	source, ok := clients.Get().(rpc.ReorgSource)
+	if !ok {
+		return nil
+	}
+	head, err := source.GetChainHead()
+	if err != nil {
+		return err
+	}
+	detector := rpc.NewReorgDetector(source)
+	for epoch := head.FinalizedEpoch + 1; epoch < head.HeadEpoch; epoch++ {
+		if !rpc.HasBlocks(epoch) {
+			continue
+		}
+		event, err := detector.Detect(epoch)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			logger.Printf("epoch %d is invalidated by the reorg of %d slots since slot %d", epoch, event.Depth, event.Slot)
+		}
+	}
+	return nil
+}
+
+// run caches the range of epochs, defined by the flags
+func run(clients *clients, since time.Time) error {
 	estHeadEpoch := 0
 	headEpoch := *head
 	if headEpoch == 0 {
 		head, err := clients.Get().GetChainHead()
 		if err != nil {
-			logger.Fatal(err)
+			return err
 		}
 		headEpoch = int(head.HeadEpoch)
 		estHeadEpoch = int(head.HeadEpoch)
@@ -130,79 +386,40 @@ func main() {
 		if estHeadEpoch == 0 {
 			head, err := clients.Get().GetChainHead()
 			if err != nil {
-				logger.Fatal(err)
+				return err
 			}
 			estHeadEpoch = int(head.HeadEpoch)
 		}
 	}
 	i := *offset
 	failures := map[uint64]int{}
-	since := time.Now()
+	unavailable := map[uint64]int{}
 	for {
 		start := time.Now()
-		cont := false
 		epoch := uint64(int(headEpoch) + i*sign)
 
-		if *cacheBalances {
-			_, err := clients.Get().GetBalancesForEpoch(int64(epoch))
-			if err != nil {
-				if _, ok := failures[epoch]; !ok {
-					failures[epoch] = 0
+		if dataset, err := cacheEpoch(clients.Get(), epoch); err != nil {
+			failures[epoch] += 1
+			logger.Printf("[%v] epoch %d error: %v, took %v\n", dataset, epoch, err, time.Since(start))
+			switch classify(err) {
+			case failureSkip:
+				failures[epoch] = clients.Len()
+			case failureUnavailable:
+				unavailable[epoch] += 1
+				if unavailable[epoch] >= clients.Len() {
+					return fmt.Errorf("epoch %d: all nodes failed: %w", epoch, err)
 				}
-				failures[epoch] += 1
-				logger.Printf("[balances] epoch %d error: %v, took %v\n", epoch, err, time.Since(start))
-				if failures[epoch] < clients.Len() {
-					// try again on other server, otherwise skip
-					clients.Next()
-				} else {
-					i++            // all hosts were requested, just skip to the next epoch
-					clients.Next() // switch anyway to a better server
-				}
-				cont = true
 			}
-		}
-		if *cacheValidators {
-			_, err := clients.Get().GetEpochValidators(uint64(epoch))
-			if err != nil {
-				if _, ok := failures[epoch]; !ok {
-					failures[epoch] = 0
-				}
-				failures[epoch] += 1
-				logger.Printf("[validators] epoch %d error: %v, took %v\n", epoch, err, time.Since(start))
-				if failures[epoch] < clients.Len() {
-					// try again on other server, otherwise skip
-					clients.Next()
-				} else {
-					i++            // all hosts were requested, just skip to the next epoch
-					clients.Next() // switch anyway to a better server
-				}
-				cont = true
+			// try again on other server, otherwise skip, switching anyway to a better server
+			clients.Next()
+			if failures[epoch] < clients.Len() {
+				continue
 			}
+			// all hosts were requested, just skip to the next epoch
+		} else {
+			logger.Printf("epoch %d took %v", epoch, time.Since(start))
 		}
 
-		if *cacheAssignments {
-			_, err := clients.Get().GetEpochAssignments(epoch)
-			if err != nil {
-				if _, ok := failures[epoch]; !ok {
-					failures[epoch] = 0
-				}
-				failures[epoch] += 1
-				logger.Printf("[assignment] epoch %d error: %v, took %v\n", epoch, err, time.Since(start))
-				if failures[epoch] < clients.Len() {
-					// try again on other server, otherwise skip
-					clients.Next()
-				} else {
-					i++            // all hosts were requested, just skip to the next epoch
-					clients.Next() // switch anyway to a better server
-				}
-				cont = true
-			}
-		}
-		if cont {
-			continue
-		}
-		logger.Printf("epoch %d took %v", epoch, time.Since(start))
-
 		i++
 		nextEpoch := int(headEpoch) + sign*i
 		if sign > 0 {
@@ -221,17 +438,9 @@ func main() {
 			maxDuration := time.Duration(*timeout) * time.Minute
 			if dur >= maxDuration {
 				logger.Printf("this takes more than %v, EXITING", maxDuration)
-				os.Exit(0)
+				return nil
 			}
 		}
 	}
-
-	if timeout != nil && *timeout > 0 {
-		dur := time.Now().Sub(since)
-		maxDuration := time.Duration(*timeout) * time.Minute
-		if dur < maxDuration {
-			logger.Printf("sleeping for %v", maxDuration-dur)
-			time.Sleep(maxDuration - dur)
-		}
-	}
+	return nil
 }
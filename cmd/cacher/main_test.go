@@ -0,0 +1,338 @@
+package main
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"beaconchain/types"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// setRange caches limit epochs from the offset of the head, till the end of the test
+func setRange(t *testing.T, from, epochs int) {
+	savedOffset, savedLimit := *offset, *limit
+	*offset, *limit = from, epochs
+	t.Cleanup(func() { *offset, *limit = savedOffset, savedLimit })
+}
+
+func TestRunCachesEpochsFromHead(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 1, 3)
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// limit is counted from the head, including the offset
+	for epoch := uint64(3); epoch <= 4; epoch++ {
+		if !rpc.HasBalances(int64(epoch)) || !rpc.HasValidators(epoch) || !rpc.HasAssignments(epoch) {
+			t.Errorf("epoch %d is not cached", epoch)
+		}
+		if !rpc.HasParticipation(epoch) || !rpc.HasChainHead(epoch) {
+			t.Errorf("participation or head of epoch %d is not cached", epoch)
+		}
+	}
+	if rpc.HasAssignments(5) || rpc.HasAssignments(2) {
+		t.Error("epochs out of the range are cached")
+	}
+	if validators, err := rpc.LoadValidators(3); err != nil || validators[10].BalanceActivation == 0 {
+		t.Errorf("activation balances are not filled by default: %v", err)
+	}
+}
+
+func TestSealProvisionalEpochs(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients.Get().SetAcceptProvisional(false)
+	setRange(t, 0, 3)
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// head epoch is 5, finalized one is 3
+	if epochs, _ := rpc.ProvisionalEpochs(); !reflect.DeepEqual(epochs, []uint64{4, 5}) {
+		t.Fatalf("unexpected provisional epochs %v", epochs)
+	}
+	if err := seal(clients); err != nil {
+		t.Fatal(err)
+	}
+	if epochs, _ := rpc.ProvisionalEpochs(); len(epochs) != 2 {
+		t.Fatalf("epochs are sealed before finalization %v", epochs)
+	}
+
+	node.Advance(types.SlotsPerEpoch)
+	calls := node.Calls("ListValidators")
+	if err := seal(clients); err != nil {
+		t.Fatal(err)
+	}
+	if epochs, _ := rpc.ProvisionalEpochs(); !reflect.DeepEqual(epochs, []uint64{5}) {
+		t.Errorf("unexpected provisional epochs after sealing %v", epochs)
+	}
+	if node.Calls("ListValidators") == calls {
+		t.Error("sealed epoch is not fetched again")
+	}
+}
+
+func TestRunAbortsWhenNodesUnavailable(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint, fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 1, 3)
+
+	node.FailAfter("ListValidatorBalances", 0)
+	if err := run(clients, time.Now()); !errors.Is(err, rpc.ErrNodeUnavailable) {
+		t.Fatalf("run is not aborted: %v", err)
+	}
+	if node.Calls("ListValidatorBalances") != 2 {
+		t.Errorf("epoch is requested %d times, expected once per node", node.Calls("ListValidatorBalances"))
+	}
+}
+
+// countingSource counts the requested balances of the source
+type countingSource struct {
+	rpc.BeaconSource
+	balances int
+}
+
+func (s *countingSource) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
+	s.balances++
+	return s.BeaconSource.GetBalancesForEpoch(epoch)
+}
+
+func TestRunSkipsNotCachedEpochs(t *testing.T) {
+	rpc.SetCacheDir(t.TempDir())
+	snapshot := &types.ChainHeadSnapshot{Epoch: 5, ChainHead: types.ChainHead{HeadEpoch: 5, HeadSlot: 191}}
+	if err := rpc.SaveChainHead(5, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	source := &countingSource{BeaconSource: rpc.NewCacheSource()}
+	clients := &clients{list: []rpc.BeaconSource{source, source}, hosts: []string{"first", "second"}}
+	setRange(t, 0, 3)
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// the epochs are skipped without asking the other node
+	if source.balances != 3 {
+		t.Errorf("balances are requested %d times, expected once per epoch", source.balances)
+	}
+	for epoch := uint64(3); epoch <= 5; epoch++ {
+		if rpc.HasBalances(int64(epoch)) || rpc.HasValidators(epoch) || rpc.HasAssignments(epoch) {
+			t.Errorf("datasets of the skipped epoch %d are cached", epoch)
+		}
+	}
+}
+
+func TestRunSkipsPrunedEpochs(t *testing.T) {
+	rpc.SetCacheDir(t.TempDir())
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/eth/v1/beacon/headers/head":
+			w.Write([]byte(`{"data": {"root": "0x01", "header": {"message": {"slot": "191"}}}}`))
+		case "/eth/v1/beacon/states/head/finality_checkpoints":
+			w.Write([]byte(`{"data": {"finalized": {"epoch": "3", "root": "0x02"}}}`))
+		default:
+			// the states are pruned by the node
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	clients, err := NewClients("rest", []string{server.URL, server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 0, 3)
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for epoch := uint64(3); epoch <= 5; epoch++ {
+		fn := "/eth/v1/beacon/states/" + strconv.FormatUint(epoch*types.SlotsPerEpoch, 10) + "/validator_balances"
+		if requests[fn] != 1 {
+			t.Errorf("balances of the pruned epoch %d are requested %d times", epoch, requests[fn])
+		}
+		if rpc.HasBalances(int64(epoch)) || rpc.HasValidators(epoch) {
+			t.Errorf("datasets of the pruned epoch %d are cached", epoch)
+		}
+	}
+}
+
+func TestRunWithCommitteeAssignments(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	// proposer duties of the standard API
+	duties := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		epoch, _ := strconv.ParseUint(path.Base(r.URL.Path), 10, 64)
+		data := make([]map[string]string, 0, types.SlotsPerEpoch)
+		for slot := epoch * types.SlotsPerEpoch; slot < (epoch+1)*types.SlotsPerEpoch; slot++ {
+			data = append(data, map[string]string{
+				"slot":            strconv.FormatUint(slot, 10),
+				"validator_index": strconv.FormatUint(node.Chain.Proposer(slot), 10),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer duties.Close()
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint + "?duties=" + duties.URL}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 1, 2)
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if !rpc.HasAssignments(4) || node.Calls("ListBeaconCommittees") != 1 || node.Calls("ListValidatorAssignments") != 0 {
+		t.Errorf("assignments are not built from the committees")
+	}
+}
+
+func TestRunCachesPerformance(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 0, 3)
+	*cachePerformance = true
+	defer func() { *cachePerformance = false }()
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// head epoch is 5, attestations of the epoch 4 can still be included
+	if rpc.HasPerformance(5) || rpc.HasPerformance(4) || !rpc.HasPerformance(3) {
+		t.Fatal("performance is cached before the next epoch is finished")
+	}
+	performance, err := rpc.LoadPerformance(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary := performance.Summary(); summary.Assigned != 1000 || summary.Included != 1000 {
+		t.Errorf("unexpected performance summary %+v", summary)
+	}
+	if !rpc.IsProvisional(rpc.FnPerformance(3)) {
+		t.Error("performance of the epoch before the not finalized one is sealed")
+	}
+}
+
+func TestRunCachesRewards(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 0, 3)
+	*cacheRewards = true
+	defer func() { *cacheRewards = false }()
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// balances of the next epoch are not known for the head one
+	if rpc.HasRewards(5) || !rpc.HasRewards(4) || !rpc.HasRewards(3) {
+		t.Fatal("rewards are not cached for the started epochs only")
+	}
+	rewards, err := rpc.LoadRewards(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards.Validators) != 1000 || rewards.Total.Total() != 1000*10000 {
+		t.Errorf("unexpected rewards total %+v", rewards.Total)
+	}
+	if rpc.HasDayRewards(0) {
+		t.Error("rollup of the incomplete day is cached")
+	}
+}
+
+func TestRunCachesProposals(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300, MissedSlots: []uint64{100}})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 0, 3)
+	*cacheProposals = true
+	defer func() { *cacheProposals = false }()
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// head epoch is 5, its proposals are not complete
+	if rpc.HasProposals(5) || !rpc.HasProposals(3) {
+		t.Fatal("proposals are cached before the epoch is finished")
+	}
+	proposals, err := rpc.LoadProposals(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := proposals.Get(100); !ok || p.Status != types.ProposalMissed || len(proposals.Slots) != 32 {
+		t.Errorf("unexpected proposals %+v", proposals)
+	}
+	// orphaned blocks can still appear till the finalization
+	if rpc.IsProvisional(rpc.FnProposals(3)) || !rpc.IsProvisional(rpc.FnProposals(4)) {
+		t.Error("proposals of the not finalized epoch are sealed")
+	}
+}
+
+func TestInvalidateReorgs(t *testing.T) {
+	node := rpctest.NewNode(t, fakenode.Config{Validators: 1000, Epochs: 6, PageSize: 300})
+
+	clients, err := NewClients("prysm", []string{fakenode.Endpoint}, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRange(t, 0, 3)
+	*cacheBlocks = true
+	defer func() { *cacheBlocks = false }()
+
+	if err := run(clients, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// head epoch is 5, finalized one is 3
+	if !rpc.HasBlocks(3) || !rpc.HasBlocks(4) {
+		t.Fatal("blocks are not cached")
+	}
+	if err := invalidateReorgs(clients); err != nil {
+		t.Fatal(err)
+	}
+	if !rpc.HasBlocks(4) || !rpc.HasValidators(4) {
+		t.Fatal("epoch is invalidated without a reorg")
+	}
+
+	node.Reorg(140)
+	if err := invalidateReorgs(clients); err != nil {
+		t.Fatal(err)
+	}
+	if rpc.HasBlocks(4) || rpc.HasValidators(4) || rpc.HasAssignments(5) {
+		t.Error("reorged epochs are not invalidated")
+	}
+	if !rpc.HasBlocks(3) || !rpc.HasValidators(3) {
+		t.Error("epoch before the reorg is invalidated")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"beaconchain/analysis"
+	"beaconchain/rpc"
+	"beaconchain/types"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.New().WithField("module", "proposals")
+
+var api = flag.String("api", "cache", "API of the host: prysm (v1alpha1 gRPC), rest (standard /eth/v1) or cache (offline, host is ignored)")
+var host = flag.String("host", "localhost:4000", "host to connect to, a gRPC host with ?duties=<standard API URL> gets the assignments from the beacon committees, with ?mixes=<standard API URL> computes them from the RANDAO mixes")
+var cacheDir = flag.String("cache", "/cache", "folder of the cache files")
+var from = flag.Int64("from", -1, "first epoch of the range, the range before the last epoch by default")
+var to = flag.Int64("to", -1, "last epoch of the range, the head epoch by default")
+var epochs = flag.Uint64("epochs", types.EpochsPerDay, "number of epochs in the range, when the first epoch is not set")
+var report = flag.String("report", "stats", "report to output: stats of the proposers or missed proposals")
+var format = flag.String("format", "json", "output format: json or csv")
+
+// run writes the report of the proposals of the epochs in the format
+func run(source rpc.BeaconSource, first, last uint64, report, format string, w io.Writer) error {
+	proposals, err := rpc.GetProposals(source, first, last)
+	if err != nil {
+		return err
+	}
+	var header []string
+	var rows [][]string
+	var out interface{}
+	switch report {
+	case "stats":
+		stats := analysis.ProposerStats(proposals)
+		header = []string{"index", "assigned", "proposed", "missed", "orphaned", "scheduled"}
+		for _, s := range stats {
+			rows = append(rows, []string{
+				strconv.FormatUint(s.Index, 10),
+				strconv.FormatUint(s.Assigned, 10),
+				strconv.FormatUint(s.Proposed, 10),
+				strconv.FormatUint(s.Missed, 10),
+				strconv.FormatUint(s.Orphaned, 10),
+				strconv.FormatUint(s.Scheduled, 10),
+			})
+		}
+		out = stats
+	case "missed":
+		missed := analysis.MissedProposals(proposals)
+		header = []string{"slot", "proposer", "status", "block_root"}
+		for _, p := range missed {
+			rows = append(rows, []string{
+				strconv.FormatUint(p.Slot, 10),
+				strconv.FormatUint(p.Proposer, 10),
+				p.Status.String(),
+				fmt.Sprintf("%x", p.BlockRoot),
+			})
+		}
+		out = missed
+	default:
+		return fmt.Errorf("unknown report %q, expected stats or missed", report)
+	}
+
+	switch format {
+	case "json":
+		return json.NewEncoder(w).Encode(map[string]interface{}{
+			"from_epoch": first,
+			"to_epoch":   last,
+			report:       out,
+		})
+	case "csv":
+		return writeCSV(w, header, rows)
+	}
+	return fmt.Errorf("unknown format %q, expected json or csv", format)
+}
+
+// writeCSV writes the rows with the header
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	out := csv.NewWriter(w)
+	out.Write(header)
+	out.WriteAll(rows)
+	return out.Error()
+}
+
+func main() {
+	flag.Parse()
+	rpc.SetCacheDir(*cacheDir)
+
+	source, err := rpc.NewSource(*api, *host)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer source.Close()
+
+	first, last, err := rpc.EpochRange(source, *from, *to, *epochs)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if err := run(source, first, last, *report, *format, os.Stdout); err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestRunWritesMissedCSV(t *testing.T) {
+	source, node := rpctest.NewSource(t, fakenode.Config{Validators: 100, Epochs: 4, MissedSlots: []uint64{40, 75}})
+
+	first, last, err := rpc.EpochRange(source, -1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 1 || last != 2 {
+		t.Fatalf("range %d-%d", first, last)
+	}
+	var out bytes.Buffer
+	if err := run(source, first, last, "missed", "csv", &out); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[1][0] != "40" || records[2][0] != "75" || records[1][2] != "missed" {
+		t.Fatalf("unexpected records %v", records)
+	}
+	if records[1][1] != strconv.FormatUint(node.Chain.Proposer(40), 10) {
+		t.Errorf("proposer of the slot 40 is %v", records[1][1])
+	}
+}
+
+func TestRunWritesStatsJSON(t *testing.T) {
+	source, _ := rpctest.NewSource(t, fakenode.Config{Validators: 100, Epochs: 4, MissedSlots: []uint64{40}})
+
+	var out bytes.Buffer
+	if err := run(source, 1, 2, "stats", "json", &out); err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Stats []struct {
+			Assigned uint64 `json:"assigned"`
+			Proposed uint64 `json:"proposed"`
+			Missed   uint64 `json:"missed"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	var assigned, proposed, missed uint64
+	for _, s := range body.Stats {
+		assigned, proposed, missed = assigned+s.Assigned, proposed+s.Proposed, missed+s.Missed
+	}
+	if assigned != 64 || proposed != 63 || missed != 1 {
+		t.Errorf("assigned %d, proposed %d, missed %d", assigned, proposed, missed)
+	}
+	if err := run(source, 1, 2, "slots", "json", &out); err == nil {
+		t.Error("unknown report is written")
+	}
+}
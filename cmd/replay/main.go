@@ -0,0 +1,34 @@
+package main
+
+import (
+	"beaconchain/rpc/recording"
+	"flag"
+	"net"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+var logger = logrus.New().WithField("module", "replay")
+
+var dir = flag.String("dir", "fixtures", "folder with the responses, recorded by cacher -record")
+var listen = flag.String("listen", "localhost:4000", "address to serve the gRPC of the replayed node")
+
+func main() {
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.MaxSendMsgSize(128 * 1024 * 1024))
+	replay := recording.NewReplayServer(*dir)
+	ethpb.RegisterBeaconChainServer(server, replay)
+	ethpb.RegisterNodeServer(server, replay)
+
+	logger.Printf("replaying %v on %v", *dir, *listen)
+	if err := server.Serve(listener); err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"beaconchain/analysis"
+	"beaconchain/rpc"
+	"beaconchain/types"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.New().WithField("module", "returns")
+
+var api = flag.String("api", "cache", "API of the host: prysm (v1alpha1 gRPC), rest (standard /eth/v1) or cache (offline, host is ignored)")
+var host = flag.String("host", "localhost:4000", "host to connect to, a gRPC host with ?duties=<standard API URL> gets the assignments from the beacon committees, with ?mixes=<standard API URL> computes them from the RANDAO mixes")
+var cacheDir = flag.String("cache", "/cache", "folder of the cache files")
+var from = flag.Int64("from", -1, "first epoch of the window, the window before the last epoch by default")
+var to = flag.Int64("to", -1, "last epoch of the window, the head epoch by default")
+var window = flag.String("window", "30d", "length of the window, when the first epoch is not set, like 1h, 1d, 30d or 365d")
+var samples = flag.Int("samples", 4, "number of epochs between the ends of the window, where the effective balances are sampled")
+var group = flag.String("group", "network", "returns to output: validator, credentials or network")
+var format = flag.String("format", "json", "output format: json or csv")
+var listen = flag.String("listen", "", "address to serve the returns on /returns, with the query parameters named as the flags")
+
+// query is the window and the output of the returns
+type query struct {
+	from    int64
+	to      int64
+	window  string
+	samples int
+	group   string
+	format  string
+}
+
+// epochs resolves the window of the query, the last epoch defaults to the head epoch
+// and the first one is the length of the window before it
+func (q *query) epochs(source rpc.BeaconSource) (uint64, uint64, error) {
+	span := uint64(0)
+	if q.from < 0 {
+		windows, err := rpc.ParseLookbacks(q.window)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(windows) != 1 || windows[0].Activation {
+			return 0, 0, fmt.Errorf("window %q is not a duration", q.window)
+		}
+		// the range includes both ends of the window
+		span = windows[0].Epochs + 1
+	}
+	return rpc.EpochRange(source, q.from, q.to, span)
+}
+
+// run computes the returns of the query and writes them in its format
+func run(source rpc.BeaconSource, q *query, w io.Writer) error {
+	first, last, err := q.epochs(source)
+	if err != nil {
+		return err
+	}
+	report, err := analysis.Returns(source, first, last, q.samples)
+	if err != nil {
+		return err
+	}
+	var rows []*types.Return
+	switch q.group {
+	case "validator":
+		rows = report.Validators
+	case "credentials":
+		rows = report.Credentials
+	case "network":
+		rows = []*types.Return{report.Network}
+	default:
+		return fmt.Errorf("unknown group %q, expected validator, credentials or network", q.group)
+	}
+	switch q.format {
+	case "json":
+		return json.NewEncoder(w).Encode(map[string]interface{}{
+			"from_epoch": report.FromEpoch,
+			"to_epoch":   report.ToEpoch,
+			"returns":    rows,
+		})
+	case "csv":
+		return writeCSV(w, rows)
+	}
+	return fmt.Errorf("unknown format %q, expected json or csv", q.format)
+}
+
+// writeCSV writes the returns with the header
+func writeCSV(w io.Writer, rows []*types.Return) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"key", "validators", "start_balance", "end_balance", "deposits", "income", "stake_epochs", "apr", "apy"})
+	for _, r := range rows {
+		out.Write([]string{
+			r.Key,
+			strconv.Itoa(r.Validators),
+			strconv.FormatUint(r.StartBalance, 10),
+			strconv.FormatUint(r.EndBalance, 10),
+			strconv.FormatUint(r.Deposits, 10),
+			strconv.FormatInt(r.Income, 10),
+			strconv.FormatFloat(r.StakeEpochs, 'f', 0, 64),
+			strconv.FormatFloat(r.APR, 'f', 6, 64),
+			strconv.FormatFloat(r.APY, 'f', 6, 64),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// handler serves the returns of the query parameters, the flags are the defaults
+func handler(source rpc.BeaconSource, defaults query) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := defaults
+		params := r.URL.Query()
+		var err error
+		for name, target := range map[string]*int64{"from": &q.from, "to": &q.to} {
+			if value := params.Get(name); value != "" && err == nil {
+				*target, err = strconv.ParseInt(value, 10, 64)
+			}
+		}
+		if value := params.Get("samples"); value != "" && err == nil {
+			q.samples, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for name, target := range map[string]*string{"window": &q.window, "group": &q.group, "format": &q.format} {
+			if value := params.Get(name); value != "" {
+				*target = value
+			}
+		}
+		// the returns are written once they are complete, so the failure is not mixed into them
+		var out bytes.Buffer
+		if err := run(source, &q, &out); err != nil {
+			logger.Errorf("returns %v: %v", r.URL.RawQuery, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if q.format == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Write(out.Bytes())
+	}
+}
+
+func main() {
+	flag.Parse()
+	rpc.SetCacheDir(*cacheDir)
+
+	source, err := rpc.NewSource(*api, *host)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer source.Close()
+
+	q := query{from: *from, to: *to, window: *window, samples: *samples, group: *group, format: *format}
+	if *listen != "" {
+		http.Handle("/returns", handler(source, q))
+		logger.Printf("serving returns on %v", *listen)
+		logger.Fatal(http.ListenAndServe(*listen, nil))
+	}
+	if err := run(source, &q, os.Stdout); err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newSource(t *testing.T) rpc.BeaconSource {
+	source, _ := rpctest.NewSource(t, fakenode.Config{Validators: 100, Epochs: 12})
+	return source
+}
+
+func TestRunWritesCSV(t *testing.T) {
+	source := newSource(t)
+
+	var out bytes.Buffer
+	if err := run(source, &query{from: 2, to: 10, samples: 2, group: "validator", format: "csv"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 101 || records[0][0] != "key" || records[1][0] != "0" || records[1][5] != "80000" {
+		t.Errorf("unexpected records %v", records[:2])
+	}
+}
+
+func TestHandlerServesJSON(t *testing.T) {
+	source := newSource(t)
+	server := httptest.NewServer(handler(source, query{from: -1, to: -1, window: "30d", samples: 4, group: "network", format: "json"}))
+	defer server.Close()
+
+	// 1h is 9 epochs of 384 seconds
+	res, err := http.Get(server.URL + "?to=10&window=1h&group=credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var body struct {
+		FromEpoch uint64 `json:"from_epoch"`
+		ToEpoch   uint64 `json:"to_epoch"`
+		Returns   []struct {
+			Validators int   `json:"validators"`
+			Income     int64 `json:"income"`
+		} `json:"returns"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.FromEpoch != 1 || body.ToEpoch != 10 || len(body.Returns) != 100 || body.Returns[0].Income != 9*10000 {
+		t.Errorf("unexpected returns %+v", body)
+	}
+
+	res, err = http.Get(server.URL + "?group=validators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("unknown group: status %d", res.StatusCode)
+	}
+
+	// the failed returns are not written before the error
+	res, err = http.Get(server.URL + "?from=11&to=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusInternalServerError || res.Header.Get("Content-Type") == "application/json" {
+		t.Errorf("empty range: status %d, content %v", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+}
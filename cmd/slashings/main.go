@@ -0,0 +1,79 @@
+package main
+
+import (
+	"beaconchain/analysis"
+	"beaconchain/rpc"
+	"beaconchain/types"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.New().WithField("module", "slashings")
+
+var api = flag.String("api", "cache", "API of the host: prysm (v1alpha1 gRPC), rest (standard /eth/v1) or cache (offline, host is ignored)")
+var host = flag.String("host", "localhost:4000", "host to connect to, a gRPC host with ?duties=<standard API URL> gets the assignments from the beacon committees, with ?mixes=<standard API URL> computes them from the RANDAO mixes")
+var cacheDir = flag.String("cache", "/cache", "folder of the cache files")
+var from = flag.Int64("from", -1, "first epoch of the range, the range before the last epoch by default")
+var to = flag.Int64("to", -1, "last epoch of the range, the head epoch by default")
+var epochs = flag.Uint64("epochs", types.EpochsPerDay, "number of epochs in the range, when the first epoch is not set")
+var report = flag.String("report", "crosscheck", "report to output: events (slashings of the network), offences (double and surround votes of the attestations) or crosscheck (offences against the slashings)")
+
+// run writes the report of the slashings of the epochs as JSON
+func run(source rpc.BeaconSource, first, last uint64, report string, w io.Writer) error {
+	var out interface{}
+	switch report {
+	case "events":
+		events, err := analysis.SlashingEvents(source, first, last)
+		if err != nil {
+			return err
+		}
+		out = events
+	case "offences":
+		offences, err := analysis.DetectOffences(source, first, last)
+		if err != nil {
+			return err
+		}
+		out = offences
+	case "crosscheck":
+		events, err := analysis.SlashingEvents(source, first, last)
+		if err != nil {
+			return err
+		}
+		offences, err := analysis.DetectOffences(source, first, last)
+		if err != nil {
+			return err
+		}
+		out = analysis.CrossCheck(offences, events)
+	default:
+		return fmt.Errorf("unknown report %q, expected events, offences or crosscheck", report)
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"from_epoch": first,
+		"to_epoch":   last,
+		report:       out,
+	})
+}
+
+func main() {
+	flag.Parse()
+	rpc.SetCacheDir(*cacheDir)
+
+	source, err := rpc.NewSource(*api, *host)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer source.Close()
+
+	first, last, err := rpc.EpochRange(source, *from, *to, *epochs)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if err := run(source, first, last, *report, os.Stdout); err != nil {
+		logger.Fatal(err)
+	}
+}
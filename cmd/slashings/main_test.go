@@ -0,0 +1,63 @@
+package main
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/rpctest"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRunWritesCrossCheck(t *testing.T) {
+	source, _ := rpctest.NewSource(t, fakenode.Config{Validators: 100, Epochs: 4, Slashings: map[uint64]uint64{40: 7}})
+
+	first, last, err := rpc.EpochRange(source, -1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 1 || last != 2 {
+		t.Fatalf("range %d-%d", first, last)
+	}
+	var out bytes.Buffer
+	if err := run(source, first, last, "crosscheck", &out); err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Check struct {
+			Slashed    []uint64      `json:"slashed"`
+			Undetected []interface{} `json:"undetected"`
+		} `json:"crosscheck"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	// the double proposal is not an offence of the votes
+	if len(body.Check.Slashed) != 0 || len(body.Check.Undetected) != 0 {
+		t.Errorf("slashed %v, undetected %v", body.Check.Slashed, body.Check.Undetected)
+	}
+
+	out.Reset()
+	if err := run(source, first, last, "events", &out); err != nil {
+		t.Fatal(err)
+	}
+	var events struct {
+		Events []struct {
+			Slot      uint64 `json:"slot"`
+			Validator uint64 `json:"validator"`
+			Kind      string `json:"kind"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Events) != 1 || events.Events[0].Slot != 40 || events.Events[0].Validator != 7 || events.Events[0].Kind != "double_proposal" {
+		t.Errorf("unexpected events %v", events.Events)
+	}
+	if err := run(source, first, last, "votes", &out); err == nil {
+		t.Error("unknown report is written")
+	}
+	if _, _, err := rpc.EpochRange(source, 3, 2, 2); err == nil {
+		t.Error("empty range is resolved")
+	}
+}
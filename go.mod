@@ -12,5 +12,6 @@ require (
 	github.com/prysmaticlabs/ethereumapis v0.0.0-20210520130538-2cf083a48639
 	github.com/prysmaticlabs/go-bitfield v0.0.0-20210515192923-def021850363
 	github.com/sirupsen/logrus v1.8.1
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	google.golang.org/grpc v1.38.0
-)
\ No newline at end of file
+)
@@ -7,7 +7,6 @@ import (
 	"compress/gzip"
 	"encoding/binary"
 	"encoding/gob"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -19,7 +18,7 @@ import (
 var logassignments = logrus.New().WithField("module", "assignments")
 
 func FnAssignments(epoch uint64) string {
-	return fmt.Sprintf("/cache/%d.assign.gz", epoch)
+	return CachePath(fmt.Sprintf("%d.assign.gz", epoch))
 }
 
 func HasAssignments(epoch uint64) bool {
@@ -43,6 +42,7 @@ func NewAssignmentsFromPB(epoch uint64, src []*ethpb.ValidatorAssignments) *type
 	proposers := map[uint64]uint64{}
 	slotsz := map[uint64]uint64{}
 	firstSlot := uint64(0)
+	firstSlotFound := false
 
 	numAssignments := 0
 	// loop 1 - define sizes for allocations
@@ -52,18 +52,17 @@ func NewAssignmentsFromPB(epoch uint64, src []*ethpb.ValidatorAssignments) *type
 		for i := 0; i < len(src[ai].Assignments); i++ {
 			assignment := src[ai].Assignments[i]
 
-			if len(assignment.ProposerSlots) > 0 {
-				slot := uint64(assignment.ProposerSlots[0])
-				if slot < firstSlot || firstSlot == 0 {
-					firstSlot = slot
+			// the validator can propose more than one slot of the epoch
+			for _, proposerSlot := range assignment.ProposerSlots {
+				slot := uint64(proposerSlot)
+				if slot < firstSlot || !firstSlotFound {
+					firstSlot, firstSlotFound = slot, true
 				}
-				proposer := uint64(assignment.ValidatorIndex)
-				proposers[uint64(slot)] = proposer
-				// log.Printf("%d ProposerSlots: %v %v", i, slot, proposer)
+				proposers[slot] = uint64(assignment.ValidatorIndex)
 			}
 			slot := uint64(assignment.AttesterSlot)
-			if slot < firstSlot || firstSlot == 0 {
-				firstSlot = slot
+			if slot < firstSlot || !firstSlotFound {
+				firstSlot, firstSlotFound = slot, true
 			}
 
 			if val, ok := slotsz[slot]; ok {
@@ -76,13 +75,25 @@ func NewAssignmentsFromPB(epoch uint64, src []*ethpb.ValidatorAssignments) *type
 		}
 		// fmt.Printf("slotsz %v proposers: %v", slotsz, proposers)
 	}
-	// step 2 - allocation
-	assignments := make([]types.AssignmentSlot, uint32(len(slotsz)))
-	for slot, maxCommitteeIndex := range slotsz {
-		slotIndex := uint64(slot) - firstSlot
+	// step 2 - allocation, for every slot of the range, including the slots without attesters
+	numSlots := uint64(0)
+	for _, slots := range []map[uint64]uint64{slotsz, proposers} {
+		for slot := range slots {
+			if slot-firstSlot+1 > numSlots {
+				numSlots = slot - firstSlot + 1
+			}
+		}
+	}
+	assignments := make([]types.AssignmentSlot, numSlots)
+	for slotIndex := range assignments {
+		slot := firstSlot + uint64(slotIndex)
+		numCommittees := uint64(0)
+		if maxCommitteeIndex, ok := slotsz[slot]; ok {
+			numCommittees = maxCommitteeIndex + 1
+		}
 		assignments[slotIndex] = types.AssignmentSlot{
-			Proposer:   proposers[uint64(slot)],
-			Committees: make([][]uint64, uint64(maxCommitteeIndex)+1),
+			Proposer:   proposers[slot],
+			Committees: make([][]uint64, numCommittees),
 		}
 	}
 
@@ -101,19 +112,59 @@ func NewAssignmentsFromPB(epoch uint64, src []*ethpb.ValidatorAssignments) *type
 	// logassignments.Printf("encoding %v assignments from PB for epoch %v starting from slot %v took %v",
 	// numAssignments, epoch, firstSlot, time.Since(since))
 
-	return &types.Assignments{
+	out := &types.Assignments{
 		Epoch:          uint32(epoch),
 		FirstSlot:      firstSlot,
 		NumSlots:       uint32(len(proposers)),
 		NumAssignments: uint64(numAssignments),
 		Assignments:    assignments,
 	}
+	out.SetDuties(types.NewDutyIndex(out))
+	return out
+}
+
+// NewAssignmentsFromCommittees builds assignments from the proposer and committees
+// of every slot of the epoch, where committees are indexed by slot and committee index
+func NewAssignmentsFromCommittees(epoch uint64, proposers map[uint64]uint64, committees map[uint64]map[uint64][]uint64) *types.Assignments {
+	firstSlot := epoch * types.SlotsPerEpoch
+	numAssignments := 0
+	assignments := make([]types.AssignmentSlot, types.SlotsPerEpoch)
+	for slotIndex := range assignments {
+		slot := firstSlot + uint64(slotIndex)
+		slotCommittees := committees[slot]
+		// empty committees have no assignments, like the ones of the validators
+		numCommittees := uint64(0)
+		for committeeIndex, members := range slotCommittees {
+			if len(members) > 0 && committeeIndex+1 > numCommittees {
+				numCommittees = committeeIndex + 1
+			}
+		}
+		assignments[slotIndex] = types.AssignmentSlot{
+			Proposer:   proposers[slot],
+			Committees: make([][]uint64, numCommittees),
+		}
+		for committeeIndex, members := range slotCommittees {
+			if len(members) > 0 {
+				assignments[slotIndex].Committees[committeeIndex] = members
+				numAssignments += len(members)
+			}
+		}
+	}
+	out := &types.Assignments{
+		Epoch:          uint32(epoch),
+		FirstSlot:      firstSlot,
+		NumSlots:       uint32(len(proposers)),
+		NumAssignments: uint64(numAssignments),
+		Assignments:    assignments,
+	}
+	out.SetDuties(types.NewDutyIndex(out))
+	return out
 }
 
 func LoadAssignments(epoch uint64) (*types.Assignments, error) {
 	file, err := os.Open(FnAssignments(epoch))
 	if err != nil {
-		return nil, err
+		return nil, cacheError(err, "assignments", epoch)
 	}
 	defer file.Close()
 	stats, statsErr := file.Stat()
@@ -123,7 +174,7 @@ func LoadAssignments(epoch uint64) (*types.Assignments, error) {
 
 	var size int64 = stats.Size()
 	if size <= 255 {
-		return nil, errors.New("empty storage")
+		return nil, emptyStorage(FnAssignments(epoch))
 	}
 	file, err = os.Open(FnAssignments(epoch))
 	if err != nil {
@@ -148,9 +199,27 @@ func LoadAssignments(epoch uint64) (*types.Assignments, error) {
 	if err != nil {
 		return nil, err
 	}
+	out.SetDuties(loadDuties(&out))
 	return &out, nil
 }
 
+// loadDuties returns the cached index of the assignments, it is built again when it is missing
+func loadDuties(a *types.Assignments) *types.DutyIndex {
+	epoch := uint64(a.Epoch)
+	if HasDuties(epoch) {
+		duties, err := LoadDuties(epoch)
+		switch {
+		case err != nil:
+			logassignments.Errorf("LoadDuties failure: %v", err)
+		case duties.Epoch != epoch:
+			logassignments.Errorf("duties cached for epoch %d are of epoch %d", epoch, duties.Epoch)
+		default:
+			return duties
+		}
+	}
+	return types.NewDutyIndex(a)
+}
+
 func SaveAssignments(epoch uint64, src *types.Assignments) error {
 	// start := time.Now()
 
@@ -175,7 +244,9 @@ func SaveAssignments(epoch uint64, src *types.Assignments) error {
 	if err := binary.Write(gz, binary.LittleEndian, bb.Bytes()); err != nil {
 		return err
 	}
-	defer gz.Close()
+	if err := gz.Close(); err != nil {
+		return err
+	}
 	// logassignments.Printf("saving of epoch %v took %v", epoch, time.Since(start))
-	return nil
+	return SaveDuties(epoch, src.Duties())
 }
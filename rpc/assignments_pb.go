@@ -12,9 +12,9 @@ import (
 
 func FnAssignmentsPB(epoch uint64, pageToken string) string {
 	if len(pageToken) > 0 {
-		return fmt.Sprintf("/cache/%d-%s.assign.pb", epoch, pageToken)
+		return CachePath(fmt.Sprintf("%d-%s.assign.pb", epoch, pageToken))
 	}
-	return fmt.Sprintf("/cache/%d.assign.pb", epoch)
+	return CachePath(fmt.Sprintf("%d.assign.pb", epoch))
 }
 
 func HasAssignmentsPB(epoch uint64) bool {
@@ -37,7 +37,7 @@ func LoadAssignmentsPB(epoch uint64, pageToken string) (*ethpb.ValidatorAssignme
 	start := time.Now()
 	data, err := ioutil.ReadFile(FnAssignmentsPB(epoch, pageToken))
 	if err != nil {
-		return nil, err
+		return nil, cacheError(err, "assignments", epoch)
 	}
 	var message ethpb.ValidatorAssignments
 	err = proto.Unmarshal(data, &message)
@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -16,7 +15,7 @@ import (
 var logbalances = logrus.New().WithField("module", "balances")
 
 func FnBalances(epoch int64) string {
-	return fmt.Sprintf("/cache/%d.balances.gz", epoch)
+	return CachePath(fmt.Sprintf("%d.balances.gz", epoch))
 }
 
 func HasBalances(epoch int64) bool {
@@ -38,7 +37,7 @@ func HasBalances(epoch int64) bool {
 func LoadBalances(epoch int64) (map[uint64]uint64, error) {
 	file, err := os.Open(FnBalances(epoch))
 	if err != nil {
-		return nil, err
+		return nil, cacheError(err, "balances", uint64(epoch))
 	}
 	defer file.Close()
 	stats, statsErr := file.Stat()
@@ -48,7 +47,7 @@ func LoadBalances(epoch int64) (map[uint64]uint64, error) {
 
 	var size int64 = stats.Size()
 	if size <= 255 {
-		return nil, errors.New("empty storage")
+		return nil, emptyStorage(FnBalances(epoch))
 	}
 	file, err = os.Open(FnBalances(epoch))
 	if err != nil {
@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logblocks = logrus.New().WithField("module", "blocks")
+
+func FnBlocks(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.blocks.gz", epoch))
+}
+
+// FnReplacedBlocks keeps the blocks of the invalidated epoch, till they are merged into the fetched ones
+func FnReplacedBlocks(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.replaced.blocks.gz", epoch))
+}
+
+func HasBlocks(epoch uint64) bool {
+	return hasFile(FnBlocks(epoch))
+}
+
+func LoadBlocks(epoch uint64) ([]*types.Block, error) {
+	return loadBlocksFile(FnBlocks(epoch), epoch)
+}
+
+func loadBlocksFile(fn string, epoch uint64) ([]*types.Block, error) {
+	start := time.Now()
+	var out []*types.Block
+	if err := loadGob(fn, &out); err != nil {
+		return nil, cacheError(err, "blocks", epoch)
+	}
+	for _, b := range out {
+		restoreBlock(b)
+	}
+	logblocks.Infof("%d blocks loaded from cache of epoch %d within %v", len(out), epoch, time.Since(start))
+	return out, nil
+}
+
+func SaveBlocks(epoch uint64, src []*types.Block) error {
+	if len(src) == 0 || epoch <= 0 {
+		return nil
+	}
+	return saveGob(FnBlocks(epoch), src)
+}
+
+// restoreBlock brings back the empty lists and structs, which gob decodes as nil
+func restoreBlock(b *types.Block) {
+	if b.Eth1Data == nil {
+		b.Eth1Data = &types.Eth1Data{}
+	}
+	if b.ProposerSlashings == nil {
+		b.ProposerSlashings = make([]*types.ProposerSlashing, 0)
+	}
+	if b.AttesterSlashings == nil {
+		b.AttesterSlashings = make([]*types.AttesterSlashing, 0)
+	}
+	if b.Attestations == nil {
+		b.Attestations = make([]*types.Attestation, 0)
+	}
+	if b.Deposits == nil {
+		b.Deposits = make([]*types.Deposit, 0)
+	}
+	if b.VoluntaryExits == nil {
+		b.VoluntaryExits = make([]*types.VoluntaryExit, 0)
+	}
+	for _, a := range b.Attestations {
+		if a.Attesters == nil {
+			a.Attesters = make([]uint64, 0)
+		}
+	}
+}
+
+// retainBlocks adds the blocks of the cache files, which are not fetched, as orphaned ones,
+// so the competing blocks of the slots are kept after the reorgs and the pruning of the node
+func retainBlocks(epoch uint64, fetched []*types.Block, fns ...string) []*types.Block {
+	known := make(map[string]bool, len(fetched))
+	for _, b := range fetched {
+		known[string(b.BlockRoot)] = true
+	}
+	out := fetched
+	for _, fn := range fns {
+		if !hasFile(fn) {
+			continue
+		}
+		cached, err := loadBlocksFile(fn, epoch)
+		if err != nil {
+			logblocks.Errorf("retained blocks of epoch %d failure: %v", epoch, err)
+			continue
+		}
+		for _, b := range cached {
+			if known[string(b.BlockRoot)] {
+				continue
+			}
+			known[string(b.BlockRoot)] = true
+			b.Canonical, b.Status = false, types.BlockOrphaned
+			out = append(out, b)
+		}
+	}
+	if len(out) != len(fetched) {
+		logblocks.Infof("%d orphaned blocks of epoch %d are retained", len(out)-len(fetched), epoch)
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Slot < out[j].Slot })
+	}
+	return out
+}
+
+// replaceBlocks moves the cached blocks of the invalidated epoch aside, merged with the ones replaced before
+func replaceBlocks(epoch uint64) error {
+	if !HasBlocks(epoch) {
+		return nil
+	}
+	replaced := retainBlocks(epoch, nil, FnBlocks(epoch), FnReplacedBlocks(epoch))
+	if len(replaced) == 0 {
+		return nil
+	}
+	return saveGob(FnReplacedBlocks(epoch), replaced)
+}
+
+// savedBlocks drops the replaced blocks of the epoch, which are merged into the saved ones
+func savedBlocks(epoch uint64) {
+	if err := os.Remove(FnReplacedBlocks(epoch)); err != nil && !os.IsNotExist(err) {
+		logblocks.Errorf("replaced blocks of epoch %d failure: %v", epoch, err)
+	}
+}
+
+// fetchBlocks requests blocks of every slot of the epoch, at most limit slots at once
+func fetchBlocks(limit int, epoch uint64, getBlocksBySlot func(slot uint64) ([]*types.Block, error)) ([]*types.Block, error) {
+	slotBlocks := make([][]*types.Block, types.SlotsPerEpoch)
+	err := parallel(limit, types.SlotsPerEpoch, func(i int) error {
+		blocks, err := getBlocksBySlot(epoch*types.SlotsPerEpoch + uint64(i))
+		slotBlocks[i] = blocks
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*types.Block, 0, types.SlotsPerEpoch)
+	for _, blocks := range slotBlocks {
+		out = append(out, blocks...)
+	}
+	return out, nil
+}
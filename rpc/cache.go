@@ -1,7 +1,11 @@
 package rpc
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
 	"os"
+	"path/filepath"
 
 	"github.com/EnormousCloud/objstorage/pkg/s3object"
 )
@@ -16,3 +20,87 @@ func NewStorage() (IStorage, error) {
 	return s3object.FromEnv(os.Getenv("AWS_S3_BUCKET"), "cache/")
 	// return localobject.NewLocalObject("/tmp/cache"), nil
 }
+
+var cacheDir = "/cache"
+
+// SetCacheDir changes the folder of the local cache files, /cache by default
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// CachePath returns full path of the file in the local cache
+func CachePath(name string) string {
+	return filepath.Join(cacheDir, name)
+}
+
+// hasFile tells whether the cache file exists and is not empty
+func hasFile(fn string) bool {
+	stats, err := os.Stat(fn)
+	return err == nil && stats.Size() > 0
+}
+
+// loadGob decodes the gzipped gob cache file into out
+func loadGob(fn string, out interface{}) error {
+	file, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return gob.NewDecoder(zr).Decode(out)
+}
+
+// saveGob writes src as the gzipped gob cache file
+func saveGob(fn string, src interface{}) error {
+	var bb bytes.Buffer
+	if err := gob.NewEncoder(&bb).Encode(src); err != nil {
+		return err
+	}
+	file, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	if _, err := gz.Write(bb.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// epochFiles are the cache files derived from the chain at the epoch
+func epochFiles(epoch uint64) []string {
+	return []string{
+		FnAssignments(epoch),
+		FnAssignmentsPB(epoch, ""),
+		FnDuties(epoch),
+		FnValidators(epoch),
+		FnLookback(epoch),
+		FnBalances(int64(epoch)),
+		FnBlocks(epoch),
+		FnParticipation(epoch),
+		FnPerformance(epoch),
+		FnRewards(epoch),
+		FnProposals(epoch),
+	}
+}
+
+// InvalidateEpoch removes the cached data of the epoch, so it is fetched again
+func InvalidateEpoch(epoch uint64) error {
+	// blocks of the abandoned fork are kept as orphaned, when the node does not return them again
+	if err := replaceBlocks(epoch); err != nil {
+		return err
+	}
+	for _, fn := range epochFiles(epoch) {
+		for _, name := range []string{fn, FnProvisional(fn)} {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBalancesCodec(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	chain := fakenode.NewChain(fakenode.Config{Validators: 500, Epochs: 2})
+
+	src := make(map[uint64]uint64)
+	for index := uint64(0); index < 500; index++ {
+		src[index], _ = chain.Balance(index, 1)
+	}
+	if err := SaveBalances(1, src); err != nil {
+		t.Fatal(err)
+	}
+	if !HasBalances(1) {
+		t.Fatal("balances are not cached")
+	}
+	out, err := LoadBalances(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src, out) {
+		t.Error("balances mismatch after round trip")
+	}
+}
+
+func TestAssignmentsCodec(t *testing.T) {
+	client, _ := newFakeClient(t, fakenode.Config{Validators: 500, Epochs: 2})
+
+	src, err := client.GetEpochAssignments(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := LoadAssignments(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src, out) {
+		t.Error("assignments mismatch after round trip")
+	}
+	if !HasDuties(1) {
+		t.Fatal("duties are not saved with the assignments")
+	}
+
+	// the index of the assignments cached without it is built on load
+	if err := os.Remove(FnDuties(1)); err != nil {
+		t.Fatal(err)
+	}
+	out, err = LoadAssignments(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src.Duties(), out.Duties()) {
+		t.Error("duties mismatch after rebuilding")
+	}
+}
+
+func TestValidatorsCodec(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	chain := fakenode.NewChain(fakenode.Config{Validators: 500, Epochs: 2, Slashings: map[uint64]uint64{33: 5}})
+
+	src := make([]types.ValidatorF, 0)
+	for index := uint64(0); index < 500; index++ {
+		v, _ := chain.Validator(index, 1)
+		balance, _ := chain.Balance(index, 1)
+		val := types.ValidatorF{
+			Index:                      index,
+			Balance:                    balance,
+			EffectiveBalance:           v.EffectiveBalance,
+			Slashed:                    v.Slashed,
+			ActivationEligibilityEpoch: uint64(v.ActivationEligibilityEpoch),
+			ActivationEpoch:            uint64(v.ActivationEpoch),
+			ExitEpoch:                  uint64(v.ExitEpoch),
+			WithdrawableEpoch:          uint64(v.WithdrawableEpoch),
+		}
+		copy(val.PublicKey[:], v.PublicKey)
+		copy(val.WithdrawalCredentials[:], v.WithdrawalCredentials)
+		val.Status = val.StatusAt(1)
+		src = append(src, val)
+	}
+	if err := SaveValidators(1, src); err != nil {
+		t.Fatal(err)
+	}
+	out, err := LoadValidators(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src, out) {
+		t.Error("validators mismatch after round trip")
+	}
+	if !out[5].Slashed {
+		t.Error("slashed flag is lost")
+	}
+}
+
+func TestBlocksCodec(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{
+		Validators:    500,
+		Epochs:        3,
+		MissedSlots:   []uint64{34},
+		OrphanedSlots: []uint64{40},
+		Slashings:     map[uint64]uint64{45: 3},
+		Deposits:      []uint64{50},
+	})
+
+	src, err := client.GetEpochBlocks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasBlocks(1) {
+		t.Fatal("blocks are not cached")
+	}
+	out, err := LoadBlocks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src, out) {
+		t.Error("blocks mismatch after round trip")
+	}
+
+	calls := node.Calls("ListBlocks")
+	data, err := client.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListBlocks") != calls {
+		t.Error("blocks of the epoch data are not read from the cache")
+	}
+	if len(data.Blocks[40]) != 2 || len(data.Blocks[34]) != 1 {
+		t.Errorf("unexpected blocks of the epoch data")
+	}
+	for _, b := range data.Blocks[40] {
+		if b.Canonical != (b.Status == types.BlockProposed) || (!b.Canonical && b.Status != types.BlockOrphaned) {
+			t.Errorf("block at slot 40 has status %d, canonical %v", b.Status, b.Canonical)
+		}
+	}
+
+	// blocks of the head epoch are not complete yet
+	node.Advance(1)
+	if _, err := client.GetEpochBlocks(3); err != nil {
+		t.Fatal(err)
+	}
+	if HasBlocks(3) {
+		t.Error("unfinished epoch is cached")
+	}
+
+	// the small blocks files are loaded as well
+	if err := SaveBlocks(5, []*types.Block{{Slot: 160}}); err != nil {
+		t.Fatal(err)
+	}
+	if small, err := LoadBlocks(5); !HasBlocks(5) || err != nil || len(small) != 1 {
+		t.Errorf("small blocks file is not loaded: %v", err)
+	}
+}
+
+func TestBlocksRetainedAfterReorg(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 500, Epochs: 4})
+
+	if _, err := client.GetEpochBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+	oldRoot := node.Chain.CanonicalRoot(70)
+	node.Reorg(70)
+	// the node forgets the replaced blocks
+	node.PruneOrphans()
+	if err := client.InvalidateEpoch(2); err != nil {
+		t.Fatal(err)
+	}
+	if HasBlocks(2) || !hasFile(FnReplacedBlocks(2)) {
+		t.Fatal("blocks of the invalidated epoch are not moved aside")
+	}
+
+	blocks, err := client.GetEpochBlocks(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphaned, canonical := 0, 0
+	for _, b := range blocks {
+		if b.Canonical {
+			canonical++
+			continue
+		}
+		orphaned++
+		if b.Status != types.BlockOrphaned || b.Slot < 70 {
+			t.Errorf("unexpected retained block %d with status %d", b.Slot, b.Status)
+		}
+		if b.Slot == 70 && !bytes.Equal(b.BlockRoot, oldRoot) {
+			t.Errorf("unexpected root of the replaced block %x", b.BlockRoot)
+		}
+	}
+	if canonical != 32 || orphaned != 26 {
+		t.Errorf("%d canonical and %d orphaned blocks", canonical, orphaned)
+	}
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i-1].Slot > blocks[i].Slot {
+			t.Fatalf("blocks are not sorted at %d", i)
+		}
+	}
+	cached, err := LoadBlocks(2)
+	if err != nil || len(cached) != len(blocks) {
+		t.Errorf("%d blocks are cached, %v", len(cached), err)
+	}
+	if hasFile(FnReplacedBlocks(2)) {
+		t.Error("replaced blocks are kept after they are merged")
+	}
+}
+
+func TestParticipationCache(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 500, Epochs: 4})
+
+	if _, err := client.GetValidatorParticipation(3); err == nil {
+		t.Error("participation of the unfinished epoch must fail")
+	}
+	if HasParticipation(3) {
+		t.Error("failed participation is cached")
+	}
+
+	src, err := client.GetValidatorParticipation(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := node.Calls("GetValidatorParticipation")
+	out, err := client.GetValidatorParticipation(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("GetValidatorParticipation") != calls {
+		t.Error("participation is not read from the cache")
+	}
+	if !reflect.DeepEqual(src, out) || !out.Finalized || out.EligibleEther == 0 {
+		t.Errorf("participation mismatch after round trip %+v", out)
+	}
+}
@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"context"
+	"fmt"
+
+	eth2types "github.com/prysmaticlabs/eth2-types"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// ProposerDutiesSource provides the proposers of the slots of the epoch
+type ProposerDutiesSource interface {
+	// GetProposerDuties returns the proposer index by slot of the epoch
+	GetProposerDuties(epoch uint64) (map[uint64]uint64, error)
+}
+
+var _ ProposerDutiesSource = (*RestClient)(nil)
+
+// SetProposerDuties makes the client build the assignments from the beacon committees of the epoch
+// and the proposer duties of the source, which takes a single request instead of paging
+// through the assignments of every validator. Nil restores the paging
+func (pc *PrysmClient) SetProposerDuties(duties ProposerDutiesSource) {
+	pc.proposerDuties = duties
+}
+
+// fetchCommitteeAssignments builds the assignments from the beacon committees and the proposer duties
+func (pc *PrysmClient) fetchCommitteeAssignments(epoch uint64) (*types.Assignments, error) {
+	proposers, err := pc.proposerDuties.GetProposerDuties(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving proposer duties: %w", err)
+	}
+
+	request := &ethpb.ListCommitteesRequest{QueryFilter: &ethpb.ListCommitteesRequest_Epoch{Epoch: eth2types.Epoch(epoch)}}
+	if epoch == 0 {
+		request.QueryFilter = &ethpb.ListCommitteesRequest_Genesis{Genesis: true}
+	}
+	response, err := pc.client.ListBeaconCommittees(context.Background(), request)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving committees: %w", err)
+	}
+	committees := make(map[uint64]map[uint64][]uint64, len(response.Committees))
+	for slot, list := range response.Committees {
+		committees[slot] = make(map[uint64][]uint64, len(list.Committees))
+		for committeeIndex, committee := range list.Committees {
+			committees[slot][uint64(committeeIndex)] = validatorIndexes(committee.ValidatorIndices)
+		}
+	}
+	return NewAssignmentsFromCommittees(epoch, proposers, committees), nil
+}
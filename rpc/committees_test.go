@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"reflect"
+	"testing"
+)
+
+// chainDuties provides the proposer duties of the fake chain
+type chainDuties struct {
+	chain *fakenode.Chain
+}
+
+func (d chainDuties) GetProposerDuties(epoch uint64) (map[uint64]uint64, error) {
+	res := make(map[uint64]uint64, types.SlotsPerEpoch)
+	for slot := epoch * types.SlotsPerEpoch; slot < (epoch+1)*types.SlotsPerEpoch; slot++ {
+		res[slot] = d.chain.Proposer(slot)
+	}
+	return res, nil
+}
+
+func TestCommitteeAssignmentsParity(t *testing.T) {
+	// few validators propose several slots of the epoch
+	for _, cfg := range []fakenode.Config{
+		{Validators: 20, Epochs: 4},
+		{Validators: 5000, Epochs: 4, PageSize: 300, Deposits: []uint64{40}},
+	} {
+		client, node := newFakeClient(t, cfg)
+		client.SetProposerDuties(chainDuties{node.Chain})
+		for epoch := uint64(0); epoch < 4; epoch++ {
+			byValidators, err := client.fetchValidatorAssignments(epoch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			byCommittees, err := client.fetchCommitteeAssignments(epoch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(byValidators, byCommittees) {
+				t.Errorf("%d validators: assignments of epoch %d differ", cfg.Validators, epoch)
+			}
+		}
+	}
+}
+
+func TestPrysmClientCommitteeAssignments(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4, PageSize: 300})
+	client.SetProposerDuties(chainDuties{node.Chain})
+
+	assignments, err := client.GetEpochAssignments(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListBeaconCommittees") != 1 || node.Calls("ListValidatorAssignments") != 0 {
+		t.Errorf("unexpected requests: %d committees, %d assignments",
+			node.Calls("ListBeaconCommittees"), node.Calls("ListValidatorAssignments"))
+	}
+	if !HasAssignments(2) || assignments.Assignments[5].Proposer != node.Chain.Proposer(69) {
+		t.Error("assignments are not cached or wrong")
+	}
+}
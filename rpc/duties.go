@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"fmt"
+)
+
+// FnDuties is the reverse index of the assignments, saved alongside them
+func FnDuties(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.duties.gz", epoch))
+}
+
+func HasDuties(epoch uint64) bool {
+	return hasFile(FnDuties(epoch))
+}
+
+func LoadDuties(epoch uint64) (*types.DutyIndex, error) {
+	var out types.DutyIndex
+	if err := loadGob(FnDuties(epoch), &out); err != nil {
+		return nil, cacheError(err, "duties", epoch)
+	}
+	return &out, nil
+}
+
+func SaveDuties(epoch uint64, src *types.DutyIndex) error {
+	if src == nil || epoch <= 0 {
+		return nil
+	}
+	return saveGob(FnDuties(epoch), src)
+}
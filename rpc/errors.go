@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotSupported is returned when the node API has no way to provide the data
+var ErrNotSupported = errors.New("not supported by the node API")
+
+// ErrNotCached is matched by the errors of the data missing in the cache
+var ErrNotCached = errors.New("not cached")
+
+// ErrEmptyStorage is matched by the errors of the cache files too small to hold any data
+var ErrEmptyStorage = errors.New("empty storage")
+
+// ErrNotFound is matched by the errors of the data the node does not have, like the pruned states
+var ErrNotFound = errors.New("not found by the node")
+
+// ErrNodeUnavailable is matched by the errors of the node, which cannot be reached or does not answer in time
+var ErrNodeUnavailable = errors.New("node unavailable")
+
+// ErrPartialPagination is matched by the errors of the paged list, interrupted after some of its pages
+var ErrPartialPagination = errors.New("partial pagination")
+
+// NotCachedError tells which data is missing in the cache
+type NotCachedError struct {
+	Dataset string
+	Epoch   uint64
+}
+
+func (e *NotCachedError) Error() string {
+	return fmt.Sprintf("%v of epoch %d: %v", e.Dataset, e.Epoch, ErrNotCached)
+}
+
+// Is makes errors.Is(err, ErrNotCached) true
+func (e *NotCachedError) Is(target error) bool {
+	return target == ErrNotCached
+}
+
+// cacheError tells the missing cache file apart from the other failures to read it
+func cacheError(err error, dataset string, epoch uint64) error {
+	if os.IsNotExist(err) {
+		return &NotCachedError{Dataset: dataset, Epoch: epoch}
+	}
+	return err
+}
+
+// emptyStorage is the error of the cache file without data
+func emptyStorage(fn string) error {
+	return fmt.Errorf("%v: %w", fn, ErrEmptyStorage)
+}
+
+// NodeError is the failed request to the node
+type NodeError struct {
+	Method string
+	Err    error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Method, e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrNodeUnavailable) true when the node could not answer,
+// rather than rejected the request, and errors.Is(err, ErrNotFound) when it has no such data
+func (e *NodeError) Is(target error) bool {
+	switch target {
+	case ErrNodeUnavailable:
+		return unavailable(e.Err)
+	case ErrNotFound:
+		return status.Code(e.Err) == codes.NotFound
+	}
+	return false
+}
+
+// GRPCStatus keeps the status code of the failed gRPC request
+func (e *NodeError) GRPCStatus() *status.Status {
+	return status.Convert(e.Err)
+}
+
+// httpStatusError is the unexpected status of the REST response
+type httpStatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %v", e.Status)
+}
+
+func unavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var statusErr *httpStatusError
+	return errors.As(err, &statusErr) && statusErr.Code >= 500
+}
+
+// PartialError is the paged list of the epoch, which failed after some of its pages were received
+type PartialError struct {
+	Dataset string
+	Epoch   uint64
+	Pages   int
+	Err     error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%v of epoch %d: %v after %d pages: %v", e.Dataset, e.Epoch, ErrPartialPagination, e.Pages, e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrPartialPagination) true
+func (e *PartialError) Is(target error) bool {
+	return target == ErrPartialPagination
+}
+
+// pageError is the failure of the paged list, partial once some pages were received
+func pageError(dataset string, epoch uint64, pages int, err error) error {
+	if pages == 0 {
+		return err
+	}
+	return &PartialError{Dataset: dataset, Epoch: epoch, Pages: pages, Err: err}
+}
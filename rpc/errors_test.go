@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadErrors(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	if _, err := LoadBalances(5); !errors.Is(err, ErrNotCached) {
+		t.Errorf("missing balances: %v", err)
+	}
+	if _, err := LoadParticipation(5); !errors.Is(err, ErrNotCached) {
+		t.Errorf("missing participation: %v", err)
+	}
+	if err := ioutil.WriteFile(FnValidators(5), []byte("short"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadValidators(5)
+	if !errors.Is(err, ErrEmptyStorage) || errors.Is(err, ErrNotCached) {
+		t.Errorf("empty validators: %v", err)
+	}
+}
+
+func TestPrysmClientPartialPagination(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4, PageSize: 300})
+
+	node.FailAfter("ListValidatorBalances", 1)
+	_, err := client.GetBalancesForEpoch(2)
+	if !errors.Is(err, ErrPartialPagination) || !errors.Is(err, ErrNodeUnavailable) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var partial *PartialError
+	if !errors.As(err, &partial) || partial.Pages != 1 || partial.Dataset != "balances" {
+		t.Errorf("unexpected partial error %+v", partial)
+	}
+	if HasBalances(2) {
+		t.Error("partial balances are cached")
+	}
+
+	// failure of the first page is not partial
+	_, err = client.GetBalancesForEpoch(1)
+	if errors.Is(err, ErrPartialPagination) || !errors.Is(err, ErrNodeUnavailable) {
+		t.Errorf("unexpected error of the first page %v", err)
+	}
+}
+
+func TestPrysmClientNodeErrors(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 64, Epochs: 4})
+
+	// the rejected request is not an unavailable node
+	_, err := client.GetValidatorParticipation(10)
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.Method != "GetValidatorParticipation" || errors.Is(err, ErrNodeUnavailable) {
+		t.Errorf("unexpected error of the rejected request %v", err)
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("status code %v is lost", status.Code(err))
+	}
+
+	node.Close()
+	if _, err := client.GetChainHead(); !errors.Is(err, ErrNodeUnavailable) {
+		t.Errorf("unexpected error of the stopped node %v", err)
+	}
+}
+
+func TestRestClientNodeErrors(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "syncing", http.StatusServiceUnavailable)
+	}))
+	client, err := NewRestClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := client.GetChainHead(); !errors.Is(err, ErrNodeUnavailable) {
+		t.Errorf("unexpected error of the syncing node %v", err)
+	}
+	server.Close()
+	if _, err := client.GetGenesisTimestamp(); !errors.Is(err, ErrNodeUnavailable) {
+		t.Errorf("unexpected error of the stopped node %v", err)
+	}
+}
+
+func TestRestClientNotFound(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	// the state of the epoch is pruned or not reached by the node
+	_, err := client.GetBalancesForEpoch(50)
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrNodeUnavailable) {
+		t.Errorf("unexpected error of the missing state %v", err)
+	}
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.Method != "GET /eth/v1/beacon/states/1600/validator_balances" {
+		t.Errorf("request of the missing state is lost %v", err)
+	}
+}
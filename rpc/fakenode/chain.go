@@ -0,0 +1,537 @@
+package fakenode
+
+import (
+	"beaconchain/types"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/go-bitfield"
+
+	eth2types "github.com/prysmaticlabs/eth2-types"
+)
+
+// FarFutureEpoch is the epoch of the events that are not scheduled yet
+const FarFutureEpoch = math.MaxUint64
+
+// MaxEffectiveBalance of a validator in Gwei
+const MaxEffectiveBalance = 32000000000
+
+// Config describes the synthetic chain
+type Config struct {
+	// Validators is the number of validators active since genesis
+	Validators int
+	// Epochs is the number of produced epochs, head is the last slot of the last epoch
+	Epochs uint64
+	// PageSize is the maximum size of the page served, 0 to follow the requested size
+	PageSize int
+	// MissedSlots are the slots without a block
+	MissedSlots []uint64
+	// OrphanedSlots have a non-canonical block competing with the canonical one
+	OrphanedSlots []uint64
+	// Slashings maps the slot to the validator, which is slashed by the block of that slot
+	Slashings map[uint64]uint64
+	// Deposits are the slots which blocks include a deposit of a new validator
+	Deposits []uint64
+	// GenesisTime is the unix timestamp of the genesis
+	GenesisTime int64
+}
+
+type validator struct {
+	index        uint64
+	depositEpoch uint64
+	slashedEpoch uint64
+}
+
+// Chain is a deterministic synthetic beacon chain,
+// its readers must not run concurrently with Advance, Node takes care of it
+type Chain struct {
+	cfg          Config
+	validators   []*validator
+	blocks       map[uint64][]*ethpb.BeaconBlockContainer
+	roots        [][]byte
+	headSlot     uint64
+	shuffling    map[uint64][]uint64
+	shufflingMux sync.Mutex
+	mux          sync.RWMutex
+	fork         int
+	deposited    map[uint64]uint64
+}
+
+// NewChain generates the chain for the configuration
+func NewChain(cfg Config) *Chain {
+	if cfg.Epochs == 0 {
+		cfg.Epochs = 1
+	}
+	c := &Chain{
+		cfg:       cfg,
+		blocks:    make(map[uint64][]*ethpb.BeaconBlockContainer),
+		shuffling: make(map[uint64][]uint64),
+		deposited: make(map[uint64]uint64),
+	}
+	for i := 0; i < cfg.Validators; i++ {
+		c.validators = append(c.validators, &validator{
+			index:        uint64(i),
+			slashedEpoch: FarFutureEpoch,
+		})
+	}
+	for slot := uint64(0); slot < cfg.Epochs*types.SlotsPerEpoch; slot++ {
+		c.produce(slot)
+	}
+	return c
+}
+
+// Advance produces the next slots of the chain
+func (c *Chain) Advance(slots uint64) {
+	for i := uint64(0); i < slots; i++ {
+		c.produce(c.headSlot + 1)
+	}
+}
+
+// Reorg replaces canonical blocks since the slot with the blocks of a new fork up to the same head,
+// the replaced blocks stay known as orphaned
+func (c *Chain) Reorg(fromSlot uint64) {
+	head := c.headSlot
+	if fromSlot == 0 || fromSlot > head {
+		return
+	}
+	for slot := fromSlot; slot <= head; slot++ {
+		for _, container := range c.blocks[slot] {
+			container.Canonical = false
+		}
+	}
+	c.fork++
+	c.roots = c.roots[:fromSlot]
+	for slot := fromSlot; slot <= head; slot++ {
+		c.produce(slot)
+	}
+}
+
+// PruneOrphans forgets the blocks, which are not canonical, like a node pruning the finalized forks
+func (c *Chain) PruneOrphans() {
+	for slot, containers := range c.blocks {
+		canonical := containers[:0]
+		for _, container := range containers {
+			if container.Canonical {
+				canonical = append(canonical, container)
+			}
+		}
+		c.blocks[slot] = canonical
+	}
+}
+
+// forkTag distinguishes the blocks of the forks, produced by Reorg
+func (c *Chain) forkTag() string {
+	if c.fork == 0 {
+		return ""
+	}
+	return fmt.Sprintf("-fork-%d", c.fork)
+}
+
+// Config returns the configuration of the chain
+func (c *Chain) Config() Config {
+	return c.cfg
+}
+
+func hasSlot(list []uint64, slot uint64) bool {
+	for _, s := range list {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// fill returns deterministic pseudo-random bytes of the given size
+func fill(size int, tag string, args ...interface{}) []byte {
+	seed := []byte(fmt.Sprintf(tag, args...))
+	out := make([]byte, 0, size+sha256.Size)
+	for i := 0; len(out) < size; i++ {
+		h := sha256.Sum256(append(seed, byte(i)))
+		out = append(out, h[:]...)
+	}
+	return out[:size]
+}
+
+// PublicKey returns the public key of the validator
+func PublicKey(index uint64) []byte {
+	return fill(48, "pubkey-%d", index)
+}
+
+// WithdrawalCredentials returns the withdrawal credentials of the validator
+func WithdrawalCredentials(index uint64) []byte {
+	return fill(32, "wc-%d", index)
+}
+
+func epochOf(slot uint64) uint64 {
+	return slot / types.SlotsPerEpoch
+}
+
+// HeadSlot returns the last slot of the chain
+func (c *Chain) HeadSlot() uint64 {
+	return c.headSlot
+}
+
+// CanonicalRoot returns root of the latest canonical block at or before the slot
+func (c *Chain) CanonicalRoot(slot uint64) []byte {
+	if slot >= uint64(len(c.roots)) {
+		slot = uint64(len(c.roots)) - 1
+	}
+	return c.roots[slot]
+}
+
+// Blocks returns all blocks of the slot, canonical block goes first
+func (c *Chain) Blocks(slot uint64) []*ethpb.BeaconBlockContainer {
+	return c.blocks[slot]
+}
+
+// NumValidators returns number of validators known at the epoch
+func (c *Chain) NumValidators(epoch uint64) int {
+	n := 0
+	for _, v := range c.validators {
+		if v.depositEpoch <= epoch {
+			n++
+		}
+	}
+	return n
+}
+
+// Validator returns the state of the validator at the epoch
+func (c *Chain) Validator(index uint64, epoch uint64) (*ethpb.Validator, bool) {
+	if index >= uint64(len(c.validators)) || c.validators[index].depositEpoch > epoch {
+		return nil, false
+	}
+	v := c.validators[index]
+	res := &ethpb.Validator{
+		PublicKey:                  PublicKey(index),
+		WithdrawalCredentials:      WithdrawalCredentials(index),
+		EffectiveBalance:           MaxEffectiveBalance,
+		ActivationEligibilityEpoch: 0,
+		ActivationEpoch:            0,
+		ExitEpoch:                  FarFutureEpoch,
+		WithdrawableEpoch:          FarFutureEpoch,
+	}
+	if v.depositEpoch > 0 {
+		res.ActivationEligibilityEpoch = eth2types.Epoch(v.depositEpoch + 1)
+		res.ActivationEpoch = eth2types.Epoch(v.depositEpoch + 4)
+	}
+	if v.slashedEpoch <= epoch {
+		res.Slashed = true
+		res.EffectiveBalance = MaxEffectiveBalance - 1000000000
+		res.ExitEpoch = eth2types.Epoch(v.slashedEpoch + 4)
+		res.WithdrawableEpoch = eth2types.Epoch(v.slashedEpoch + 8192)
+	}
+	return res, true
+}
+
+func isActive(v *ethpb.Validator, epoch uint64) bool {
+	return uint64(v.ActivationEpoch) <= epoch && epoch < uint64(v.ExitEpoch)
+}
+
+// Balance returns the balance of the validator at the epoch
+func (c *Chain) Balance(index uint64, epoch uint64) (uint64, bool) {
+	v, ok := c.Validator(index, epoch)
+	if !ok {
+		return 0, false
+	}
+	if epoch < uint64(v.ActivationEpoch) {
+		return MaxEffectiveBalance, true
+	}
+	last := epoch
+	if slashed := c.validators[index].slashedEpoch; slashed <= epoch {
+		last = slashed
+	}
+	balance := uint64(MaxEffectiveBalance) + (last-uint64(v.ActivationEpoch))*10000 + index
+	if v.Slashed {
+		balance -= 1000000000
+	}
+	return balance, true
+}
+
+// ActiveIndices returns sorted indices of the validators active at the epoch
+func (c *Chain) ActiveIndices(epoch uint64) []uint64 {
+	res := make([]uint64, 0, len(c.validators))
+	for index := range c.validators {
+		if v, ok := c.Validator(uint64(index), epoch); ok && isActive(v, epoch) {
+			res = append(res, uint64(index))
+		}
+	}
+	return res
+}
+
+// shuffled returns active indices of the epoch in a deterministic order
+func (c *Chain) shuffled(epoch uint64) []uint64 {
+	c.shufflingMux.Lock()
+	defer c.shufflingMux.Unlock()
+	if res, ok := c.shuffling[epoch]; ok {
+		return res
+	}
+	active := c.ActiveIndices(epoch)
+	perm := rand.New(rand.NewSource(int64(epoch) + 1)).Perm(len(active))
+	res := make([]uint64, len(active))
+	for i, p := range perm {
+		res[i] = active[p]
+	}
+	c.shuffling[epoch] = res
+	return res
+}
+
+func (c *Chain) resetShuffling() {
+	c.shufflingMux.Lock()
+	c.shuffling = make(map[uint64][]uint64)
+	c.shufflingMux.Unlock()
+}
+
+// CommitteesPerSlot returns the number of committees in each slot of the epoch
+func (c *Chain) CommitteesPerSlot(epoch uint64) uint64 {
+	n := uint64(len(c.ActiveIndices(epoch))) / types.SlotsPerEpoch / 128
+	if n < 1 {
+		return 1
+	}
+	if n > 64 {
+		return 64
+	}
+	return n
+}
+
+// Committees returns committees of the epoch, indexed by slot index and committee index
+func (c *Chain) Committees(epoch uint64) [][][]uint64 {
+	shuffled := c.shuffled(epoch)
+	perSlot := c.CommitteesPerSlot(epoch)
+	total := uint64(types.SlotsPerEpoch) * perSlot
+	size := uint64(len(shuffled))
+	res := make([][][]uint64, types.SlotsPerEpoch)
+	for slotIndex := range res {
+		res[slotIndex] = make([][]uint64, perSlot)
+		for committee := uint64(0); committee < perSlot; committee++ {
+			k := uint64(slotIndex)*perSlot + committee
+			res[slotIndex][committee] = shuffled[size*k/total : size*(k+1)/total]
+		}
+	}
+	return res
+}
+
+// Proposer returns the proposer index of the slot
+func (c *Chain) Proposer(slot uint64) uint64 {
+	shuffled := c.shuffled(epochOf(slot))
+	if len(shuffled) == 0 {
+		return 0
+	}
+	return shuffled[int(slot%types.SlotsPerEpoch)%len(shuffled)]
+}
+
+func (c *Chain) checkpoint(epoch uint64) *ethpb.Checkpoint {
+	return &ethpb.Checkpoint{
+		Epoch: eth2types.Epoch(epoch),
+		Root:  c.CanonicalRoot(epoch * types.SlotsPerEpoch),
+	}
+}
+
+// attestations returns full participation attestations for the slots in the range [from, to)
+func (c *Chain) attestations(from, to uint64) []*ethpb.Attestation {
+	res := make([]*ethpb.Attestation, 0)
+	for slot := from; slot < to; slot++ {
+		epoch := epochOf(slot)
+		source := uint64(0)
+		if epoch > 0 {
+			source = epoch - 1
+		}
+		for committeeIndex, committee := range c.Committees(epoch)[slot%types.SlotsPerEpoch] {
+			bits := bitfield.NewBitlist(uint64(len(committee)))
+			for i := range committee {
+				bits.SetBitAt(uint64(i), true)
+			}
+			res = append(res, &ethpb.Attestation{
+				AggregationBits: bits,
+				Data: &ethpb.AttestationData{
+					Slot:            eth2types.Slot(slot),
+					CommitteeIndex:  eth2types.CommitteeIndex(committeeIndex),
+					BeaconBlockRoot: c.CanonicalRoot(slot),
+					Source:          c.checkpoint(source),
+					Target:          c.checkpoint(epoch),
+				},
+				Signature: fill(96, "attestation-%d-%d", slot, committeeIndex),
+			})
+		}
+	}
+	return res
+}
+
+func header(slot uint64, proposer uint64, parent []byte, tag string) *ethpb.SignedBeaconBlockHeader {
+	return &ethpb.SignedBeaconBlockHeader{
+		Header: &ethpb.BeaconBlockHeader{
+			Slot:          eth2types.Slot(slot),
+			ProposerIndex: eth2types.ValidatorIndex(proposer),
+			ParentRoot:    parent,
+			StateRoot:     fill(32, "state-%s-%d", tag, slot),
+			BodyRoot:      fill(32, "body-%s-%d", tag, slot),
+		},
+		Signature: fill(96, "header-%s-%d", tag, slot),
+	}
+}
+
+// produce appends the block of the slot to the chain
+func (c *Chain) produce(slot uint64) {
+	epoch := epochOf(slot)
+	parentSlot := uint64(0)
+	var parent []byte
+	if slot > 0 {
+		parent = c.roots[slot-1]
+		for parentSlot = slot - 1; parentSlot > 0 && len(c.blocks[parentSlot]) == 0; parentSlot-- {
+		}
+	} else {
+		parent = make([]byte, 32)
+	}
+	c.headSlot = slot
+	if slot > 0 && hasSlot(c.cfg.MissedSlots, slot) {
+		c.roots = append(c.roots, parent)
+		return
+	}
+
+	body := &ethpb.BeaconBlockBody{
+		RandaoReveal: fill(96, "randao-%d", slot),
+		Eth1Data: &ethpb.Eth1Data{
+			DepositRoot:  fill(32, "deposit-root-%d", len(c.validators)),
+			DepositCount: uint64(len(c.validators)),
+			BlockHash:    fill(32, "eth1-%d", epoch),
+		},
+		Graffiti:          fill(32, "graffiti-%d%s", slot, c.forkTag()),
+		ProposerSlashings: make([]*ethpb.ProposerSlashing, 0),
+		AttesterSlashings: make([]*ethpb.AttesterSlashing, 0),
+		Attestations:      make([]*ethpb.Attestation, 0),
+		Deposits:          make([]*ethpb.Deposit, 0),
+		VoluntaryExits:    make([]*ethpb.SignedVoluntaryExit, 0),
+	}
+	if slot > 0 {
+		// attestations of the slots since the previous block
+		body.Attestations = c.attestations(parentSlot, slot)
+	}
+	if slashed, ok := c.cfg.Slashings[slot]; ok && slashed < uint64(len(c.validators)) {
+		body.ProposerSlashings = append(body.ProposerSlashings, &ethpb.ProposerSlashing{
+			Header_1: header(slot-1, slashed, parent, "slashing-1"),
+			Header_2: header(slot-1, slashed, parent, "slashing-2"),
+		})
+		c.validators[slashed].slashedEpoch = epoch
+		c.resetShuffling()
+	}
+	if hasSlot(c.cfg.Deposits, slot) {
+		index, replayed := c.deposited[slot]
+		if !replayed {
+			index = uint64(len(c.validators))
+		}
+		proof := make([][]byte, 33)
+		for i := range proof {
+			proof[i] = fill(32, "proof-%d-%d", index, i)
+		}
+		body.Deposits = append(body.Deposits, &ethpb.Deposit{
+			Proof: proof,
+			Data: &ethpb.Deposit_Data{
+				PublicKey:             PublicKey(index),
+				WithdrawalCredentials: WithdrawalCredentials(index),
+				Amount:                MaxEffectiveBalance,
+				Signature:             fill(96, "deposit-%d", index),
+			},
+		})
+		if !replayed {
+			c.deposited[slot] = index
+			c.validators = append(c.validators, &validator{
+				index:        index,
+				depositEpoch: epoch,
+				slashedEpoch: FarFutureEpoch,
+			})
+			c.resetShuffling()
+		}
+	}
+
+	block := &ethpb.SignedBeaconBlock{
+		Block: &ethpb.BeaconBlock{
+			Slot:          eth2types.Slot(slot),
+			ProposerIndex: eth2types.ValidatorIndex(c.Proposer(slot)),
+			ParentRoot:    parent,
+			StateRoot:     fill(32, "state-%d%s", slot, c.forkTag()),
+			Body:          body,
+		},
+		Signature: fill(96, "block-%d", slot),
+	}
+	root, err := block.Block.HashTreeRoot()
+	if err != nil {
+		panic(fmt.Sprintf("fakenode: block %d root: %v", slot, err))
+	}
+	c.blocks[slot] = append([]*ethpb.BeaconBlockContainer{{Block: block, BlockRoot: root[:], Canonical: true}}, c.blocks[slot]...)
+	c.roots = append(c.roots, root[:])
+
+	if hasSlot(c.cfg.OrphanedSlots, slot) && c.fork == 0 {
+		orphan := &ethpb.SignedBeaconBlock{
+			Block: &ethpb.BeaconBlock{
+				Slot:          eth2types.Slot(slot),
+				ProposerIndex: eth2types.ValidatorIndex(c.Proposer(slot)),
+				ParentRoot:    parent,
+				StateRoot:     fill(32, "orphan-state-%d", slot),
+				Body: &ethpb.BeaconBlockBody{
+					RandaoReveal:      body.RandaoReveal,
+					Eth1Data:          body.Eth1Data,
+					Graffiti:          fill(32, "orphan-%d", slot),
+					ProposerSlashings: make([]*ethpb.ProposerSlashing, 0),
+					AttesterSlashings: make([]*ethpb.AttesterSlashing, 0),
+					Attestations:      make([]*ethpb.Attestation, 0),
+					Deposits:          make([]*ethpb.Deposit, 0),
+					VoluntaryExits:    make([]*ethpb.SignedVoluntaryExit, 0),
+				},
+			},
+			Signature: fill(96, "orphan-%d", slot),
+		}
+		root, err := orphan.Block.HashTreeRoot()
+		if err != nil {
+			panic(fmt.Sprintf("fakenode: orphan %d root: %v", slot, err))
+		}
+		c.blocks[slot] = append(c.blocks[slot], &ethpb.BeaconBlockContainer{Block: orphan, BlockRoot: root[:], Canonical: false})
+	}
+}
+
+// ChainHead returns the head with justified and finalized checkpoints lagging behind
+func (c *Chain) ChainHead() *ethpb.ChainHead {
+	headEpoch := epochOf(c.headSlot)
+	justified, previous, finalized := uint64(0), uint64(0), uint64(0)
+	if headEpoch >= 1 {
+		justified = headEpoch - 1
+	}
+	if headEpoch >= 2 {
+		previous, finalized = headEpoch-2, headEpoch-2
+	}
+	return &ethpb.ChainHead{
+		HeadSlot:                   eth2types.Slot(c.headSlot),
+		HeadEpoch:                  eth2types.Epoch(headEpoch),
+		HeadBlockRoot:              c.CanonicalRoot(c.headSlot),
+		FinalizedSlot:              eth2types.Slot(finalized * types.SlotsPerEpoch),
+		FinalizedEpoch:             eth2types.Epoch(finalized),
+		FinalizedBlockRoot:         c.CanonicalRoot(finalized * types.SlotsPerEpoch),
+		JustifiedSlot:              eth2types.Slot(justified * types.SlotsPerEpoch),
+		JustifiedEpoch:             eth2types.Epoch(justified),
+		JustifiedBlockRoot:         c.CanonicalRoot(justified * types.SlotsPerEpoch),
+		PreviousJustifiedSlot:      eth2types.Slot(previous * types.SlotsPerEpoch),
+		PreviousJustifiedEpoch:     eth2types.Epoch(previous),
+		PreviousJustifiedBlockRoot: c.CanonicalRoot(previous * types.SlotsPerEpoch),
+	}
+}
+
+// Participation returns the participation of the validators at the epoch,
+// which is full apart from the missed slots
+func (c *Chain) Participation(epoch uint64) *ethpb.ValidatorParticipationResponse {
+	eligible := uint64(0)
+	for _, index := range c.ActiveIndices(epoch) {
+		v, _ := c.Validator(index, epoch)
+		eligible += v.EffectiveBalance
+	}
+	return &ethpb.ValidatorParticipationResponse{
+		Epoch:     eth2types.Epoch(epoch),
+		Finalized: epoch <= uint64(c.ChainHead().FinalizedEpoch),
+		Participation: &ethpb.ValidatorParticipation{
+			GlobalParticipationRate: 1,
+			VotedEther:              eligible,
+			EligibleEther:           eligible,
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package fakenode
+
+import (
+	"context"
+	"net"
+	"path"
+	"sync"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Endpoint is the address to dial the fake node with its DialOption
+const Endpoint = "bufnet"
+
+// Node is a fake beacon node, serving gRPC over the in-process connection
+type Node struct {
+	Chain    *Chain
+	listener *bufconn.Listener
+	server   *grpc.Server
+	srv      *Server
+	calls    map[string]int
+	failing  map[string]int
+	callsMux sync.Mutex
+}
+
+// Start serves the chain with the fake beacon node
+func Start(chain *Chain) *Node {
+	n := &Node{
+		Chain:    chain,
+		listener: bufconn.Listen(16 * 1024 * 1024),
+		calls:    make(map[string]int),
+		failing:  make(map[string]int),
+	}
+	n.server = grpc.NewServer(
+		grpc.MaxSendMsgSize(128*1024*1024),
+		grpc.UnaryInterceptor(n.countUnary),
+		grpc.StreamInterceptor(n.countStream),
+	)
+	n.srv = NewServer(chain)
+	ethpb.RegisterBeaconChainServer(n.server, n.srv)
+	ethpb.RegisterNodeServer(n.server, n.srv)
+	go n.server.Serve(n.listener)
+	return n
+}
+
+// StartChain generates the chain from the configuration and serves it
+func StartChain(cfg Config) *Node {
+	return Start(NewChain(cfg))
+}
+
+func (n *Node) countUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	n.callsMux.Lock()
+	method := path.Base(info.FullMethod)
+	n.calls[method]++
+	after, failing := n.failing[method]
+	if failing {
+		n.failing[method] = after - 1
+	}
+	n.callsMux.Unlock()
+	if failing && after <= 0 {
+		return nil, status.Errorf(codes.Unavailable, "%v is failing", method)
+	}
+	n.Chain.mux.RLock()
+	defer n.Chain.mux.RUnlock()
+	return handler(ctx, req)
+}
+
+func (n *Node) countStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	n.callsMux.Lock()
+	n.calls[path.Base(info.FullMethod)]++
+	n.callsMux.Unlock()
+	return handler(srv, ss)
+}
+
+// Advance produces the next slots of the chain and sends them to the open streams
+func (n *Node) Advance(slots uint64) {
+	for i := uint64(0); i < slots; i++ {
+		n.Chain.mux.Lock()
+		n.Chain.Advance(1)
+		slot := n.Chain.HeadSlot()
+		n.Chain.mux.Unlock()
+		n.srv.notify(slot)
+	}
+}
+
+// Reorg replaces canonical blocks of the chain since the slot with a new fork
+func (n *Node) Reorg(fromSlot uint64) {
+	n.Chain.mux.Lock()
+	defer n.Chain.mux.Unlock()
+	n.Chain.Reorg(fromSlot)
+}
+
+// PruneOrphans forgets the blocks of the chain, which are not canonical
+func (n *Node) PruneOrphans() {
+	n.Chain.mux.Lock()
+	defer n.Chain.mux.Unlock()
+	n.Chain.PruneOrphans()
+}
+
+// Streams returns the number of open streams
+func (n *Node) Streams() int {
+	n.srv.subsMux.Lock()
+	defer n.srv.subsMux.Unlock()
+	return len(n.srv.subs)
+}
+
+// DropStreams breaks the open streams of the node, as if it was restarted
+func (n *Node) DropStreams() {
+	n.srv.drop()
+}
+
+// Calls returns how many times the method, i.e. "ListBlocks", was called
+func (n *Node) Calls(method string) int {
+	n.callsMux.Lock()
+	defer n.callsMux.Unlock()
+	return n.calls[method]
+}
+
+// FailAfter makes the method, i.e. "ListValidators", unavailable once it is called the number of times more
+func (n *Node) FailAfter(method string, calls int) {
+	n.callsMux.Lock()
+	defer n.callsMux.Unlock()
+	n.failing[method] = calls
+}
+
+// DialOption connects the gRPC client to the node
+func (n *Node) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return n.listener.Dial()
+	})
+}
+
+// Close stops the node
+func (n *Node) Close() {
+	n.server.Stop()
+}
@@ -0,0 +1,296 @@
+package fakenode
+
+import (
+	"beaconchain/types"
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
+
+	empty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	eth2types "github.com/prysmaticlabs/eth2-types"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements v1alpha1 BeaconChain and Node services on top of the synthetic chain
+type Server struct {
+	ethpb.UnimplementedBeaconChainServer
+	ethpb.UnimplementedNodeServer
+	chain   *Chain
+	subs    map[*subscriber]struct{}
+	subsMux sync.Mutex
+}
+
+// NewServer returns the server of the chain
+func NewServer(chain *Chain) *Server {
+	return &Server{chain: chain, subs: make(map[*subscriber]struct{})}
+}
+
+// page returns the range of the requested page and the token of the next one
+func (s *Server) page(total int, pageSize int32, pageToken string) (int, int, string, error) {
+	size := int(pageSize)
+	if size <= 0 {
+		size = 250
+	}
+	if s.chain.cfg.PageSize > 0 && size > s.chain.cfg.PageSize {
+		size = s.chain.cfg.PageSize
+	}
+	page := 0
+	if pageToken != "" {
+		var err error
+		page, err = strconv.Atoi(pageToken)
+		if err != nil {
+			return 0, 0, "", status.Errorf(codes.InvalidArgument, "invalid page token %q", pageToken)
+		}
+	}
+	start := page * size
+	if start > total {
+		return 0, 0, "", status.Errorf(codes.InvalidArgument, "page start %d >= list %d", start, total)
+	}
+	end := start + size
+	next := strconv.Itoa(page + 1)
+	if end >= total {
+		end, next = total, ""
+	}
+	return start, end, next, nil
+}
+
+// requestedEpoch checks the epoch is not beyond the head of the chain
+func (s *Server) requestedEpoch(epoch uint64) (uint64, error) {
+	if head := epochOf(s.chain.HeadSlot()); epoch > head+1 {
+		return 0, status.Errorf(codes.InvalidArgument, "cannot retrieve information about an epoch in the future, current epoch %d, requesting %d", head, epoch)
+	}
+	return epoch, nil
+}
+
+// GetGenesis returns the genesis time of the chain
+func (s *Server) GetGenesis(ctx context.Context, _ *empty.Empty) (*ethpb.Genesis, error) {
+	return &ethpb.Genesis{
+		GenesisTime:           &timestamp.Timestamp{Seconds: s.chain.cfg.GenesisTime},
+		GenesisValidatorsRoot: fill(32, "genesis-validators-root"),
+	}, nil
+}
+
+// GetChainHead returns the head of the chain
+func (s *Server) GetChainHead(ctx context.Context, _ *empty.Empty) (*ethpb.ChainHead, error) {
+	return s.chain.ChainHead(), nil
+}
+
+// ListBlocks returns blocks of the slot, epoch or genesis
+func (s *Server) ListBlocks(ctx context.Context, req *ethpb.ListBlocksRequest) (*ethpb.ListBlocksResponse, error) {
+	containers := make([]*ethpb.BeaconBlockContainer, 0)
+	switch q := req.QueryFilter.(type) {
+	case *ethpb.ListBlocksRequest_Slot:
+		containers = append(containers, s.chain.Blocks(uint64(q.Slot))...)
+	case *ethpb.ListBlocksRequest_Genesis:
+		containers = append(containers, s.chain.Blocks(0)...)
+	case *ethpb.ListBlocksRequest_Root:
+		for _, blocks := range s.chain.blocks {
+			for _, container := range blocks {
+				if bytes.Equal(container.BlockRoot, q.Root) {
+					containers = append(containers, container)
+				}
+			}
+		}
+	case *ethpb.ListBlocksRequest_Epoch:
+		start := uint64(q.Epoch) * types.SlotsPerEpoch
+		for slot := start; slot < start+types.SlotsPerEpoch; slot++ {
+			containers = append(containers, s.chain.Blocks(slot)...)
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "must specify a filter criteria for fetching blocks")
+	}
+	start, end, next, err := s.page(len(containers), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ethpb.ListBlocksResponse{
+		BlockContainers: containers[start:end],
+		NextPageToken:   next,
+		TotalSize:       int32(len(containers)),
+	}, nil
+}
+
+// ListValidatorAssignments returns committee assignments of the active validators
+func (s *Server) ListValidatorAssignments(ctx context.Context, req *ethpb.ListValidatorAssignmentsRequest) (*ethpb.ValidatorAssignments, error) {
+	epoch := uint64(0)
+	if q, ok := req.QueryFilter.(*ethpb.ListValidatorAssignmentsRequest_Epoch); ok {
+		epoch = uint64(q.Epoch)
+	}
+	if _, err := s.requestedEpoch(epoch); err != nil {
+		return nil, err
+	}
+	active := s.chain.ActiveIndices(epoch)
+	start, end, next, err := s.page(len(active), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	type duty struct {
+		slot      uint64
+		committee uint64
+		members   []uint64
+	}
+	duties := make(map[uint64]duty, len(active))
+	firstSlot := epoch * types.SlotsPerEpoch
+	for slotIndex, committees := range s.chain.Committees(epoch) {
+		for committeeIndex, members := range committees {
+			for _, index := range members {
+				duties[index] = duty{firstSlot + uint64(slotIndex), uint64(committeeIndex), members}
+			}
+		}
+	}
+	proposals := make(map[uint64][]eth2types.Slot)
+	for slot := firstSlot; slot < firstSlot+types.SlotsPerEpoch; slot++ {
+		proposer := s.chain.Proposer(slot)
+		proposals[proposer] = append(proposals[proposer], eth2types.Slot(slot))
+	}
+
+	res := &ethpb.ValidatorAssignments{
+		Epoch:         eth2types.Epoch(epoch),
+		Assignments:   make([]*ethpb.ValidatorAssignments_CommitteeAssignment, 0, end-start),
+		NextPageToken: next,
+		TotalSize:     int32(len(active)),
+	}
+	for _, index := range active[start:end] {
+		d := duties[index]
+		committee := make([]eth2types.ValidatorIndex, len(d.members))
+		for i, member := range d.members {
+			committee[i] = eth2types.ValidatorIndex(member)
+		}
+		res.Assignments = append(res.Assignments, &ethpb.ValidatorAssignments_CommitteeAssignment{
+			BeaconCommittees: committee,
+			CommitteeIndex:   eth2types.CommitteeIndex(d.committee),
+			AttesterSlot:     eth2types.Slot(d.slot),
+			ProposerSlots:    proposals[index],
+			PublicKey:        PublicKey(index),
+			ValidatorIndex:   eth2types.ValidatorIndex(index),
+		})
+	}
+	return res, nil
+}
+
+// ListBeaconCommittees returns the committees of every slot of the epoch
+func (s *Server) ListBeaconCommittees(ctx context.Context, req *ethpb.ListCommitteesRequest) (*ethpb.BeaconCommittees, error) {
+	epoch := uint64(0)
+	if q, ok := req.QueryFilter.(*ethpb.ListCommitteesRequest_Epoch); ok {
+		epoch = uint64(q.Epoch)
+	}
+	if _, err := s.requestedEpoch(epoch); err != nil {
+		return nil, err
+	}
+	res := &ethpb.BeaconCommittees{
+		Epoch:                eth2types.Epoch(epoch),
+		Committees:           make(map[uint64]*ethpb.BeaconCommittees_CommitteesList, types.SlotsPerEpoch),
+		ActiveValidatorCount: uint64(len(s.chain.ActiveIndices(epoch))),
+	}
+	for slotIndex, committees := range s.chain.Committees(epoch) {
+		list := &ethpb.BeaconCommittees_CommitteesList{}
+		for _, members := range committees {
+			item := &ethpb.BeaconCommittees_CommitteeItem{ValidatorIndices: make([]eth2types.ValidatorIndex, len(members))}
+			for i, member := range members {
+				item.ValidatorIndices[i] = eth2types.ValidatorIndex(member)
+			}
+			list.Committees = append(list.Committees, item)
+		}
+		res.Committees[epoch*types.SlotsPerEpoch+uint64(slotIndex)] = list
+	}
+	return res, nil
+}
+
+// ListValidators returns the validators known at the epoch
+func (s *Server) ListValidators(ctx context.Context, req *ethpb.ListValidatorsRequest) (*ethpb.Validators, error) {
+	epoch := uint64(0)
+	if q, ok := req.QueryFilter.(*ethpb.ListValidatorsRequest_Epoch); ok {
+		epoch = uint64(q.Epoch)
+	}
+	if _, err := s.requestedEpoch(epoch); err != nil {
+		return nil, err
+	}
+	total := s.chain.NumValidators(epoch)
+	start, end, next, err := s.page(total, req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	res := &ethpb.Validators{
+		Epoch:         eth2types.Epoch(epoch),
+		ValidatorList: make([]*ethpb.Validators_ValidatorContainer, 0, end-start),
+		NextPageToken: next,
+		TotalSize:     int32(total),
+	}
+	for index := uint64(start); index < uint64(end); index++ {
+		v, _ := s.chain.Validator(index, epoch)
+		res.ValidatorList = append(res.ValidatorList, &ethpb.Validators_ValidatorContainer{
+			Index:     eth2types.ValidatorIndex(index),
+			Validator: v,
+		})
+	}
+	return res, nil
+}
+
+// ListValidatorBalances returns balances of the validators known at the epoch
+func (s *Server) ListValidatorBalances(ctx context.Context, req *ethpb.ListValidatorBalancesRequest) (*ethpb.ValidatorBalances, error) {
+	epoch := uint64(0)
+	if q, ok := req.QueryFilter.(*ethpb.ListValidatorBalancesRequest_Epoch); ok {
+		epoch = uint64(q.Epoch)
+	}
+	if _, err := s.requestedEpoch(epoch); err != nil {
+		return nil, err
+	}
+	indices := make([]uint64, 0)
+	if len(req.Indices) > 0 {
+		for _, index := range req.Indices {
+			if _, ok := s.chain.Validator(uint64(index), epoch); ok {
+				indices = append(indices, uint64(index))
+			}
+		}
+	} else {
+		for index := 0; index < s.chain.NumValidators(epoch); index++ {
+			indices = append(indices, uint64(index))
+		}
+	}
+	start, end, next, err := s.page(len(indices), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	res := &ethpb.ValidatorBalances{
+		Epoch:         eth2types.Epoch(epoch),
+		Balances:      make([]*ethpb.ValidatorBalances_Balance, 0, end-start),
+		NextPageToken: next,
+		TotalSize:     int32(len(indices)),
+	}
+	for _, index := range indices[start:end] {
+		balance, _ := s.chain.Balance(index, epoch)
+		res.Balances = append(res.Balances, &ethpb.ValidatorBalances_Balance{
+			PublicKey: PublicKey(index),
+			Index:     eth2types.ValidatorIndex(index),
+			Balance:   balance,
+		})
+	}
+	return res, nil
+}
+
+// GetValidatorParticipation returns participation of the epoch
+func (s *Server) GetValidatorParticipation(ctx context.Context, req *ethpb.GetValidatorParticipationRequest) (*ethpb.ValidatorParticipationResponse, error) {
+	epoch := uint64(0)
+	if q, ok := req.QueryFilter.(*ethpb.GetValidatorParticipationRequest_Epoch); ok {
+		epoch = uint64(q.Epoch)
+	}
+	if epoch >= epochOf(s.chain.HeadSlot()) {
+		return nil, status.Errorf(codes.InvalidArgument, "epoch %d is not finished yet", epoch)
+	}
+	return s.chain.Participation(epoch), nil
+}
+
+// AttestationPool is always empty as all attestations are included
+func (s *Server) AttestationPool(ctx context.Context, req *ethpb.AttestationPoolRequest) (*ethpb.AttestationPoolResponse, error) {
+	return &ethpb.AttestationPoolResponse{}, nil
+}
+
+// GetValidatorQueue returns the empty queue
+func (s *Server) GetValidatorQueue(ctx context.Context, _ *empty.Empty) (*ethpb.ValidatorQueue, error) {
+	return &ethpb.ValidatorQueue{ChurnLimit: 4}, nil
+}
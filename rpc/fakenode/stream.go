@@ -0,0 +1,100 @@
+package fakenode
+
+import (
+	empty "github.com/golang/protobuf/ptypes/empty"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// subscriber receives the slots produced by Advance, until it is dropped
+type subscriber struct {
+	slots   chan uint64
+	dropped chan struct{}
+}
+
+func (s *Server) subscribe() *subscriber {
+	sub := &subscriber{slots: make(chan uint64, 1024), dropped: make(chan struct{})}
+	s.subsMux.Lock()
+	s.subs[sub] = struct{}{}
+	s.subsMux.Unlock()
+	return sub
+}
+
+func (s *Server) unsubscribe(sub *subscriber) {
+	s.subsMux.Lock()
+	delete(s.subs, sub)
+	s.subsMux.Unlock()
+}
+
+// notify sends the produced slot to the open streams
+func (s *Server) notify(slot uint64) {
+	s.subsMux.Lock()
+	defer s.subsMux.Unlock()
+	for sub := range s.subs {
+		select {
+		case sub.slots <- slot:
+		default:
+			// the stream is too slow, the client has to backfill
+		}
+	}
+}
+
+// drop breaks all open streams, like a restart of the node would
+func (s *Server) drop() {
+	s.subsMux.Lock()
+	defer s.subsMux.Unlock()
+	for sub := range s.subs {
+		close(sub.dropped)
+		delete(s.subs, sub)
+	}
+}
+
+// next waits for the next produced slot of the stream
+func (s *Server) next(sub *subscriber, done <-chan struct{}) (uint64, error) {
+	select {
+	case slot := <-sub.slots:
+		return slot, nil
+	case <-sub.dropped:
+		return 0, status.Error(codes.Unavailable, "stream is dropped")
+	case <-done:
+		return 0, status.Error(codes.Canceled, "stream is closed")
+	}
+}
+
+// StreamBlocks sends every block produced after the stream was opened, canonical or not
+func (s *Server) StreamBlocks(req *ethpb.StreamBlocksRequest, stream ethpb.BeaconChain_StreamBlocksServer) error {
+	sub := s.subscribe()
+	defer s.unsubscribe(sub)
+	for {
+		slot, err := s.next(sub, stream.Context().Done())
+		if err != nil {
+			return err
+		}
+		s.chain.mux.RLock()
+		containers := s.chain.Blocks(slot)
+		s.chain.mux.RUnlock()
+		for _, container := range containers {
+			if err := stream.Send(container.Block); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamChainHead sends the head after every produced slot
+func (s *Server) StreamChainHead(_ *empty.Empty, stream ethpb.BeaconChain_StreamChainHeadServer) error {
+	sub := s.subscribe()
+	defer s.unsubscribe(sub)
+	for {
+		if _, err := s.next(sub, stream.Context().Done()); err != nil {
+			return err
+		}
+		s.chain.mux.RLock()
+		head := s.chain.ChainHead()
+		s.chain.mux.RUnlock()
+		if err := stream.Send(head); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// DefaultLookbacks are the windows of the balances 1, 7 and 31 days ago and at the activation,
+// the activation balances are requested once per activation epoch and remembered once it is finalized
+var DefaultLookbacks = mustParseLookbacks("1d,7d,31d,activation")
+
+// ParseLookbacks reads the comma-separated windows like 1h, 1d, 30d, 365d or activation,
+// with the length of the epoch of the chain
+func ParseLookbacks(names string) ([]types.Lookback, error) {
+	return types.ParseLookbacks(names, types.SecondsPerSlot*types.SlotsPerEpoch)
+}
+
+func mustParseLookbacks(names string) []types.Lookback {
+	res, err := ParseLookbacks(names)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// FnLookback is the balance history of the validators at the epoch, saved alongside the validators
+func FnLookback(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.lookback.gz", epoch))
+}
+
+func HasLookback(epoch uint64) bool {
+	return hasFile(FnLookback(epoch))
+}
+
+func LoadLookback(epoch uint64) (*types.BalanceLookback, error) {
+	var out types.BalanceLookback
+	if err := loadGob(FnLookback(epoch), &out); err != nil {
+		return nil, cacheError(err, "lookback", epoch)
+	}
+	return &out, nil
+}
+
+func SaveLookback(epoch uint64, src *types.BalanceLookback) error {
+	if src == nil || epoch <= 0 {
+		return nil
+	}
+	return saveGob(FnLookback(epoch), src)
+}
+
+// FnActivationBalances remembers the balances of the validators at their finalized activation epochs
+func FnActivationBalances() string {
+	return CachePath("activation.balances.gz")
+}
+
+func LoadActivationBalances() (map[uint64]uint64, error) {
+	out := make(map[uint64]uint64)
+	if err := loadGob(FnActivationBalances(), &out); err != nil {
+		return nil, cacheError(err, "activation balances", 0)
+	}
+	return out, nil
+}
+
+func SaveActivationBalances(src map[uint64]uint64) error {
+	return saveGob(FnActivationBalances(), src)
+}
+
+// activationMux serializes the updates of the activation balances
+var activationMux sync.Mutex
+
+// lookbacks configures the windows of the balance history of the validators
+type lookbacks struct {
+	windows []types.Lookback
+}
+
+// SetLookbacks changes the windows of the balance history of the validators, DefaultLookbacks are used by default
+func (l *lookbacks) SetLookbacks(windows []types.Lookback) {
+	l.windows = windows
+}
+
+func (l *lookbacks) lookbackWindows() []types.Lookback {
+	if l.windows == nil {
+		return DefaultLookbacks
+	}
+	return l.windows
+}
+
+// balanceLookback returns the balance history of the validators at the epoch,
+// the cached one is computed again for other windows. Balances of the windows are fetched concurrently with get
+func (l *lookbacks) balanceLookback(f *finality, limit int, epoch uint64, validators []types.ValidatorF, get func(epoch int64) (map[uint64]uint64, error)) (*types.BalanceLookback, error) {
+	windows := l.lookbackWindows()
+	if HasLookback(epoch) && f.usable(FnLookback(epoch)) {
+		out, err := LoadLookback(epoch)
+		if err == nil && reflect.DeepEqual(out.Windows, windows) {
+			return out, nil
+		}
+		if err != nil {
+			logger.Errorf("LoadLookback failure: %v", err)
+		}
+	}
+
+	epochs := make([]int64, 0, len(windows))
+	activation := false
+	for _, w := range windows {
+		if w.Activation {
+			activation = true
+		} else {
+			epochs = append(epochs, int64(epoch)-int64(w.Epochs))
+		}
+	}
+	history, err := balancesHistory(limit, epochs, get)
+	if err != nil {
+		return nil, err
+	}
+	var activationBalances map[uint64]uint64
+	if activation {
+		// the balances of the windows are not requested again
+		fetched := make(map[int64]map[uint64]uint64, len(epochs))
+		for i, e := range epochs {
+			if e < 0 {
+				e = 0
+			}
+			fetched[e] = history[i]
+		}
+		getFetched := func(e int64) (map[uint64]uint64, error) {
+			if balances, ok := fetched[e]; ok {
+				return balances, nil
+			}
+			return get(e)
+		}
+		if activationBalances, err = fetchActivationBalances(f, limit, epoch, validators, getFetched); err != nil {
+			return nil, err
+		}
+	}
+
+	out := &types.BalanceLookback{
+		Epoch:    epoch,
+		Windows:  windows,
+		Balances: make(map[uint64][]uint64, len(validators)),
+	}
+	for _, v := range validators {
+		balances := make([]uint64, len(windows))
+		k := 0
+		for i, w := range windows {
+			if w.Activation {
+				balances[i] = activationBalances[v.Index]
+				continue
+			}
+			balances[i] = history[k][v.Index]
+			k++
+		}
+		out.Balances[v.Index] = balances
+	}
+	if err := SaveLookback(epoch, out); err != nil {
+		logger.Errorf("SaveLookback failure: %v", err)
+	}
+	f.saved(FnLookback(epoch), epoch)
+	return out, nil
+}
+
+// fetchActivationBalances returns the balances of the validators, activated by the epoch, at their activation epochs,
+// the ones of the finalized epochs are remembered and not fetched again
+func fetchActivationBalances(f *finality, limit int, epoch uint64, validators []types.ValidatorF, get func(epoch int64) (map[uint64]uint64, error)) (map[uint64]uint64, error) {
+	activationMux.Lock()
+	defer activationMux.Unlock()
+
+	known, err := LoadActivationBalances()
+	if err != nil {
+		if !errors.Is(err, ErrNotCached) {
+			logger.Errorf("LoadActivationBalances failure: %v", err)
+		}
+		known = make(map[uint64]uint64)
+	}
+	missing := make(map[uint64][]uint64)
+	for _, v := range validators {
+		if _, ok := known[v.Index]; !ok && v.ActivationEpoch <= epoch {
+			missing[v.ActivationEpoch] = append(missing[v.ActivationEpoch], v.Index)
+		}
+	}
+	if len(missing) == 0 {
+		return known, nil
+	}
+	activations := make([]uint64, 0, len(missing))
+	for activation := range missing {
+		activations = append(activations, activation)
+	}
+	sort.Slice(activations, func(i, j int) bool { return activations[i] < activations[j] })
+
+	finalized := uint64(0)
+	if head, err := f.lastHead(); err == nil {
+		finalized = head.FinalizedEpoch
+	}
+	res := make(map[uint64]uint64, len(known)+len(validators))
+	for index, balance := range known {
+		res[index] = balance
+	}
+	remember := false
+	var mux sync.Mutex
+	err = parallel(limit, len(activations), func(i int) error {
+		balances, err := get(int64(activations[i]))
+		if err != nil {
+			return err
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		for _, index := range missing[activations[i]] {
+			res[index] = balances[index]
+			if activations[i] <= finalized {
+				known[index] = balances[index]
+				remember = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if remember {
+		if err := SaveActivationBalances(known); err != nil {
+			logger.Errorf("SaveActivationBalances failure: %v", err)
+		}
+	}
+	return res, nil
+}
+
+// withCachedLookback fills the balances of the validators, loaded from the cache, with the cached lookback,
+// the validators cached without it keep their balances 1, 7 and 31 days ago.
+// Nothing is fetched, the cached epochs keep the windows they were cached with
+func withCachedLookback(epoch uint64, validators []types.ValidatorF) ([]*types.Validator, error) {
+	if !HasLookback(epoch) {
+		out := make([]*types.Validator, 0, len(validators))
+		for _, v := range validators {
+			out = append(out, v.ToValidator())
+		}
+		return out, nil
+	}
+	lookback, err := LoadLookback(epoch)
+	if err != nil {
+		return nil, err
+	}
+	return withLookback(validators, lookback), nil
+}
+
+// withLookback fills the balances of the lookback windows of the validators
+func withLookback(validators []types.ValidatorF, lookback *types.BalanceLookback) []*types.Validator {
+	out := make([]*types.Validator, 0, len(validators))
+	for i := range validators {
+		lookback.Apply(&validators[i])
+		v := validators[i].ToValidator()
+		v.Lookback = lookback.Of(v.Index)
+		out = append(out, v)
+	}
+	return out
+}
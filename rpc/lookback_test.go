@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"testing"
+)
+
+func TestPrysmClientLookbacks(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 100, Epochs: 12, Deposits: []uint64{40}})
+	windows, err := ParseLookbacks("1h,1d,activation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetLookbacks(windows)
+
+	validators, err := client.GetEpochValidators(11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the deposited validator is activated at the epoch 5
+	deposited := validators[100]
+	atActivation, _ := node.Chain.Balance(100, 5)
+	hourAgo, _ := node.Chain.Balance(100, 2)
+	if deposited.BalanceActivation != atActivation || deposited.Lookback["activation"] != atActivation {
+		t.Errorf("activation balance %+v", deposited)
+	}
+	if deposited.Lookback["1h"] != hourAgo || deposited.Lookback["1d"] != 0 || deposited.Balance1d != 0 {
+		t.Errorf("balances of the windows %v", deposited.Lookback)
+	}
+	genesis, _ := node.Chain.Balance(20, 0)
+	if v := validators[20]; v.Lookback["1d"] != genesis || v.Balance1d != genesis {
+		t.Errorf("balances of the windows %v", v.Lookback)
+	}
+	if !HasLookback(11) {
+		t.Fatal("lookback is not cached")
+	}
+	if balances, err := LoadActivationBalances(); err != nil || balances[100] != atActivation {
+		t.Errorf("activation balances are not remembered: %v", err)
+	}
+
+	// the activation balances are remembered, other windows are computed again
+	calls := node.Calls("ListValidatorBalances")
+	client.SetLookbacks(DefaultLookbacks)
+	validators, err = client.GetEpochValidators(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListValidatorBalances") != calls+2 {
+		t.Errorf("%d balances requested, expected the epoch and the genesis", node.Calls("ListValidatorBalances")-calls)
+	}
+	if v := validators[100]; v.BalanceActivation != atActivation || len(v.Lookback) != 4 {
+		t.Errorf("balances of the default windows %v", v.Lookback)
+	}
+
+	// the cached validators keep the windows they are cached with, nothing is requested
+	calls = node.Calls("ListValidatorBalances")
+	validators, err = client.GetEpochValidators(11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListValidatorBalances") != calls {
+		t.Errorf("%d balances requested for the cached validators", node.Calls("ListValidatorBalances")-calls)
+	}
+	if v := validators[100]; v.BalanceActivation != atActivation || v.Lookback["1h"] != hourAgo {
+		t.Errorf("balances of the cached windows %v", v.Lookback)
+	}
+
+	cached, err := NewCacheSource().GetEpochValidators(11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := cached[100]; v.Lookback["1h"] != hourAgo || v.BalanceActivation != atActivation {
+		t.Errorf("cached balances of the windows %v", v.Lookback)
+	}
+}
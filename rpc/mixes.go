@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"beaconchain/shuffle"
+	"beaconchain/types"
+	"fmt"
+	"sort"
+)
+
+// RandaoMixSource provides the RANDAO mixes, which seed the committees and the proposers
+type RandaoMixSource interface {
+	// GetSeedMix returns the RANDAO mix the seeds of the epoch are derived from
+	GetSeedMix(epoch uint64) ([32]byte, error)
+}
+
+var _ RandaoMixSource = (*RestClient)(nil)
+
+// SetRandaoMixes makes the client compute the assignments with the shuffle of the spec
+// from the RANDAO mix of the source and the validators of the epoch, instead of asking the node.
+// It takes precedence over the proposer duties, nil restores the assignments of the node
+func (pc *PrysmClient) SetRandaoMixes(mixes RandaoMixSource) {
+	pc.randaoMixes = mixes
+}
+
+// fetchComputedAssignments computes the assignments from the RANDAO mix and the active validators of the epoch
+func (pc *PrysmClient) fetchComputedAssignments(epoch uint64) (*types.Assignments, error) {
+	mix, err := pc.randaoMixes.GetSeedMix(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving RANDAO mix: %w", err)
+	}
+	validators, err := pc.GetEpochValidators(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving validators list for epoch %v: %w", epoch, err)
+	}
+	active, effectiveBalances := activeRegistry(epoch, validators)
+	return shuffle.ComputeAssignments(epoch, mix, active, effectiveBalances), nil
+}
+
+// activeRegistry returns sorted indices of the validators active at the epoch and their effective balances
+func activeRegistry(epoch uint64, validators []*types.Validator) ([]uint64, map[uint64]uint64) {
+	active := make([]uint64, 0, len(validators))
+	effectiveBalances := make(map[uint64]uint64, len(validators))
+	for _, v := range validators {
+		if v.ActivationEpoch <= epoch && epoch < v.ExitEpoch {
+			active = append(active, v.Index)
+			effectiveBalances[v.Index] = v.EffectiveBalance
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i] < active[j] })
+	return active, effectiveBalances
+}
@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/shuffle"
+	"beaconchain/types"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// epochMixes provides a distinct RANDAO mix for every epoch and remembers the requested epochs
+type epochMixes struct {
+	requested []uint64
+}
+
+func (m *epochMixes) GetSeedMix(epoch uint64) ([32]byte, error) {
+	m.requested = append(m.requested, epoch)
+	return sha256.Sum256([]byte(fmt.Sprintf("randao-mix-%d", epoch))), nil
+}
+
+// sameAssignments compares the assignments but their duty index,
+// slots without committees are the same whether their list is nil or empty, as gob decodes them
+func sameAssignments(a, b *types.Assignments) bool {
+	if a.Epoch != b.Epoch || a.FirstSlot != b.FirstSlot || a.NumSlots != b.NumSlots ||
+		a.NumAssignments != b.NumAssignments || len(a.Assignments) != len(b.Assignments) {
+		return false
+	}
+	for i := range a.Assignments {
+		x, y := a.Assignments[i], b.Assignments[i]
+		if x.Proposer != y.Proposer || len(x.Committees) != len(y.Committees) {
+			return false
+		}
+		if len(x.Committees) > 0 && !reflect.DeepEqual(x.Committees, y.Committees) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPrysmClientComputedAssignments(t *testing.T) {
+	// the validator deposited at the slot 40 is known, but not active at the epoch 3
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4, Deposits: []uint64{40}})
+	mixes := &epochMixes{}
+	client.SetRandaoMixes(mixes)
+
+	assignments, err := client.GetEpochAssignments(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListValidatorAssignments") != 0 || node.Calls("ListBeaconCommittees") != 0 {
+		t.Error("assignments are requested from the node")
+	}
+	if !reflect.DeepEqual(mixes.requested, []uint64{3}) || assignments.NumAssignments != 1000 {
+		t.Errorf("mixes of epochs %v, %d assignments", mixes.requested, assignments.NumAssignments)
+	}
+
+	validators, err := client.GetEpochValidators(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mix, _ := mixes.GetSeedMix(3)
+	active, balances := activeRegistry(3, validators)
+	if len(active) != 1000 || len(validators) != 1001 {
+		t.Fatalf("%d active of %d validators", len(active), len(validators))
+	}
+	cached, err := LoadAssignments(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if computed := shuffle.ComputeAssignments(3, mix, active, balances); !sameAssignments(cached, computed) {
+		t.Error("cached assignments differ from the computed ones")
+	}
+	if duties := cached.Duties(); duties == nil || duties.Epoch != 3 {
+		t.Error("duties of the cached assignments")
+	}
+}
+
+// fixtureRegistry returns the active indices and the effective balances of the registry of assignments.csv
+func fixtureRegistry(n uint64) ([]uint64, map[uint64]uint64) {
+	active := make([]uint64, 0, n)
+	balances := make(map[uint64]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		switch {
+		case i%7 == 0:
+			balances[i] = 16 * 1000000000
+		case i%5 == 0:
+			balances[i] = 31 * 1000000000
+		default:
+			balances[i] = shuffle.MaxEffectiveBalance
+		}
+		if i%11 != 3 {
+			active = append(active, i)
+		}
+	}
+	return active, balances
+}
+
+// TestComputedAssignmentsMatchCached saves the independently computed assignments of the shuffle
+// fixture the way the committees of the node are saved and compares them with the computed ones
+func TestComputedAssignmentsMatchCached(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	file, err := os.Open("../shuffle/testdata/assignments.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	type fixture struct {
+		n          uint64
+		mix        [32]byte
+		proposers  map[uint64]uint64
+		committees map[uint64]map[uint64][]uint64
+	}
+	fixtures := make(map[uint64]*fixture)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v := strings.Split(line, ",")
+		epoch, _ := strconv.ParseUint(v[0], 10, 64)
+		f, ok := fixtures[epoch]
+		if !ok {
+			n, _ := strconv.ParseUint(v[1], 10, 64)
+			f = &fixture{n: n, proposers: make(map[uint64]uint64), committees: make(map[uint64]map[uint64][]uint64)}
+			if b, err := hex.DecodeString(v[2]); err != nil || copy(f.mix[:], b) != 32 {
+				t.Fatalf("mix %q: %v", v[2], err)
+			}
+			fixtures[epoch] = f
+		}
+		slot, _ := strconv.ParseUint(v[3], 10, 64)
+		f.proposers[slot], _ = strconv.ParseUint(v[4], 10, 64)
+		f.committees[slot] = make(map[uint64][]uint64)
+		for index, field := range v[5:] {
+			members := make([]uint64, 0)
+			for _, item := range strings.Split(field, ":") {
+				if item == "" {
+					continue
+				}
+				member, _ := strconv.ParseUint(item, 10, 64)
+				members = append(members, member)
+			}
+			f.committees[slot][uint64(index)] = members
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 3 {
+		t.Fatalf("%d epochs in the fixture", len(fixtures))
+	}
+
+	for epoch, f := range fixtures {
+		if err := SaveAssignments(epoch, NewAssignmentsFromCommittees(epoch, f.proposers, f.committees)); err != nil {
+			t.Fatal(err)
+		}
+		cached, err := LoadAssignments(epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		active, balances := fixtureRegistry(f.n)
+		if computed := shuffle.ComputeAssignments(epoch, f.mix, active, balances); !sameAssignments(cached, computed) {
+			t.Errorf("epoch %d: cached assignments differ from the computed ones", epoch)
+		}
+	}
+}
+
+func TestRestClientSeedMix(t *testing.T) {
+	genesis := "0x" + strings.Repeat("ab", 32)
+	mix := "0x" + strings.Repeat("cd", 32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.RequestURI() {
+		case "/eth/v1/beacon/states/genesis/randao":
+			fmt.Fprintf(w, `{"data":{"randao":%q}}`, genesis)
+		case "/eth/v1/beacon/states/160/randao?epoch=3":
+			fmt.Fprintf(w, `{"data":{"randao":%q}}`, mix)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewRestClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	for epoch, expected := range map[uint64]string{0: genesis, 1: genesis, 5: mix} {
+		res, err := client.GetSeedMix(epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if "0x"+hex.EncodeToString(res[:]) != expected {
+			t.Errorf("epoch %d: mix %x", epoch, res)
+		}
+	}
+	if _, err := client.GetSeedMix(6); err == nil {
+		t.Error("mix of the missing state")
+	}
+}
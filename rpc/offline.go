@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// CacheSource serves the beacon chain data from the cache files only, without a node,
+// the missing data is reported with ErrNotCached
+type CacheSource struct {
+	blocksCache *lru.Cache
+	finality
+}
+
+var _ BeaconSource = (*CacheSource)(nil)
+
+// NewCacheSource returns the source of the data in the cache folder
+func NewCacheSource() *CacheSource {
+	cs := &CacheSource{}
+	cs.chainHead = cs.GetChainHead
+	cs.blocksCache, _ = lru.New(4)
+	return cs
+}
+
+// Close has nothing to release
+func (cs *CacheSource) Close() {
+}
+
+// SetParallelism has no effect, there are no requests to limit
+func (cs *CacheSource) SetParallelism(limit int) {
+}
+
+// SetLookbacks has no effect, the cached windows are served
+func (cs *CacheSource) SetLookbacks(windows []types.Lookback) {
+}
+
+// cached checks the cache file can be read
+func (cs *CacheSource) cached(has bool, fn string, dataset string, epoch uint64) error {
+	if !has || !cs.usable(fn) {
+		return &NotCachedError{Dataset: dataset, Epoch: epoch}
+	}
+	return nil
+}
+
+// GetGenesisTimestamp is not cached
+func (cs *CacheSource) GetGenesisTimestamp() (int64, error) {
+	return 0, &NotCachedError{Dataset: "genesis"}
+}
+
+// GetChainHead returns the head recorded with the latest cached epoch
+func (cs *CacheSource) GetChainHead() (*types.ChainHead, error) {
+	files, err := filepath.Glob(CachePath("*.head.gz"))
+	if err != nil {
+		return nil, err
+	}
+	latest, found := uint64(0), false
+	for _, fn := range files {
+		epoch, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(fn), ".head.gz"), 10, 64)
+		if err == nil && (!found || epoch > latest) {
+			latest, found = epoch, true
+		}
+	}
+	if !found {
+		return nil, &NotCachedError{Dataset: "head"}
+	}
+	snapshot, err := LoadChainHead(latest)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot.ChainHead, nil
+}
+
+// GetEpochBlocks returns the cached blocks of the epoch
+func (cs *CacheSource) GetEpochBlocks(epoch uint64) ([]*types.Block, error) {
+	if err := cs.cached(HasBlocks(epoch), FnBlocks(epoch), "blocks", epoch); err != nil {
+		return nil, err
+	}
+	if blocks, found := cs.blocksCache.Get(epoch); found {
+		return blocks.([]*types.Block), nil
+	}
+	blocks, err := LoadBlocks(epoch)
+	if err != nil {
+		return nil, err
+	}
+	cs.blocksCache.Add(epoch, blocks)
+	return blocks, nil
+}
+
+// GetBlocksBySlot returns the blocks of the slot from the cached blocks of its epoch
+func (cs *CacheSource) GetBlocksBySlot(slot uint64) ([]*types.Block, error) {
+	blocks, err := cs.GetEpochBlocks(EpochOfSlot(slot))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*types.Block, 0)
+	for _, block := range blocks {
+		if block.Slot == slot {
+			res = append(res, block)
+		}
+	}
+	return res, nil
+}
+
+// GetEpochValidators returns the cached validator set of the epoch
+func (cs *CacheSource) GetEpochValidators(epoch uint64) ([]*types.Validator, error) {
+	if err := cs.cached(HasValidators(epoch), FnValidators(epoch), "validators", epoch); err != nil {
+		return nil, err
+	}
+	res, err := LoadValidators(epoch)
+	if err != nil {
+		return nil, err
+	}
+	return withCachedLookback(epoch, res)
+}
+
+// GetBalancesForEpoch returns the cached balances of the epoch
+func (cs *CacheSource) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
+	if epoch < 0 {
+		epoch = 0
+	}
+	if err := cs.cached(HasBalances(epoch), FnBalances(epoch), "balances", uint64(epoch)); err != nil {
+		return nil, err
+	}
+	return LoadBalances(epoch)
+}
+
+// GetEpochAssignments returns the cached assignments of the epoch
+func (cs *CacheSource) GetEpochAssignments(epoch uint64) (*types.Assignments, error) {
+	if err := cs.cached(HasAssignments(epoch), FnAssignments(epoch), "assignments", epoch); err != nil {
+		return nil, err
+	}
+	return LoadAssignments(epoch)
+}
+
+// GetValidatorParticipation returns the cached participation of the epoch
+func (cs *CacheSource) GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
+	if err := cs.cached(HasParticipation(epoch), FnParticipation(epoch), "participation", epoch); err != nil {
+		return nil, err
+	}
+	return LoadParticipation(epoch)
+}
+
+// GetEpochData assembles the epoch data from the cache
+func (cs *CacheSource) GetEpochData(epoch uint64) (*types.EpochData, error) {
+	return assembleEpochData(cs, epoch)
+}
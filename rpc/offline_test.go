@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCacheSourceEpochData(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{
+		Validators:    1000,
+		Epochs:        3,
+		MissedSlots:   []uint64{35},
+		OrphanedSlots: []uint64{40},
+	})
+	online, err := client.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := client.GetChainHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveChainHead(1, &types.ChainHeadSnapshot{Epoch: 1, ChainHead: *head}); err != nil {
+		t.Fatal(err)
+	}
+	node.Close()
+
+	source := NewCacheSource()
+	offline, err := source.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(online, offline) {
+		t.Error("epoch data from the cache differs from the node")
+	}
+	if cached, err := source.GetChainHead(); err != nil || !reflect.DeepEqual(cached, head) {
+		t.Errorf("unexpected cached head %+v, %v", cached, err)
+	}
+	if blocks, err := source.GetBlocksBySlot(40); err != nil || len(blocks) != 2 {
+		t.Errorf("unexpected blocks of the slot %v, %v", len(blocks), err)
+	}
+
+	_, err = source.GetEpochData(2)
+	if !errors.Is(err, ErrNotCached) {
+		t.Fatalf("expected ErrNotCached, got %v", err)
+	}
+	var notCached *NotCachedError
+	if !errors.As(err, &notCached) || notCached.Dataset != "assignments" || notCached.Epoch != 2 {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package rpc
+
+import "sync"
+
+// cfgParallelism is the default number of concurrent requests to a single node
+const cfgParallelism = 4
+
+// parallel calls fn for each index in [0, n), at most limit at once,
+// no new calls are started after the first failure, which is returned
+func parallel(limit int, n int, fn func(i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	var firstErr error
+	failed := func() bool {
+		mux.Lock()
+		defer mux.Unlock()
+		return firstErr != nil
+	}
+
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n && !failed(); i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(i); err != nil {
+				mux.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mux.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// balancesHistory fetches balances of the epochs concurrently, the same epoch is requested only once,
+// negative epochs are fetched as genesis
+func balancesHistory(limit int, epochs []int64, get func(epoch int64) (map[uint64]uint64, error)) ([]map[uint64]uint64, error) {
+	unique := make([]int64, 0, len(epochs))
+	position := make(map[int64]int)
+	for _, epoch := range epochs {
+		if epoch < 0 {
+			epoch = 0
+		}
+		if _, ok := position[epoch]; !ok {
+			position[epoch] = len(unique)
+			unique = append(unique, epoch)
+		}
+	}
+
+	fetched := make([]map[uint64]uint64, len(unique))
+	err := parallel(limit, len(unique), func(i int) error {
+		balances, err := get(unique[i])
+		fetched[i] = balances
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]map[uint64]uint64, len(epochs))
+	for i, epoch := range epochs {
+		if epoch < 0 {
+			epoch = 0
+		}
+		out[i] = fetched[position[epoch]]
+	}
+	return out, nil
+}
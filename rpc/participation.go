@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"fmt"
+)
+
+func FnParticipation(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.participation.gz", epoch))
+}
+
+// HasParticipation tells whether participation of the epoch is cached, the file is small, so any non-empty one counts
+func HasParticipation(epoch uint64) bool {
+	return hasFile(FnParticipation(epoch))
+}
+
+func LoadParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
+	var out types.ValidatorParticipation
+	if err := loadGob(FnParticipation(epoch), &out); err != nil {
+		return nil, cacheError(err, "participation", epoch)
+	}
+	return &out, nil
+}
+
+func SaveParticipation(epoch uint64, src *types.ValidatorParticipation) error {
+	if src == nil {
+		return nil
+	}
+	return saveGob(FnParticipation(epoch), src)
+}
+
+func FnChainHead(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.head.gz", epoch))
+}
+
+// HasChainHead tells whether the chain head was recorded when the epoch was cached
+func HasChainHead(epoch uint64) bool {
+	return hasFile(FnChainHead(epoch))
+}
+
+func LoadChainHead(epoch uint64) (*types.ChainHeadSnapshot, error) {
+	var out types.ChainHeadSnapshot
+	if err := loadGob(FnChainHead(epoch), &out); err != nil {
+		return nil, cacheError(err, "head", epoch)
+	}
+	return &out, nil
+}
+
+func SaveChainHead(epoch uint64, src *types.ChainHeadSnapshot) error {
+	if src == nil {
+		return nil
+	}
+	return saveGob(FnChainHead(epoch), src)
+}
@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"fmt"
+)
+
+// FnPerformance is the attestation performance of the validators assigned in the epoch
+func FnPerformance(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.performance.gz", epoch))
+}
+
+func HasPerformance(epoch uint64) bool {
+	return hasFile(FnPerformance(epoch))
+}
+
+func LoadPerformance(epoch uint64) (*types.EpochPerformance, error) {
+	var out types.EpochPerformance
+	if err := loadGob(FnPerformance(epoch), &out); err != nil {
+		return nil, cacheError(err, "performance", epoch)
+	}
+	return &out, nil
+}
+
+func SavePerformance(epoch uint64, src *types.EpochPerformance) error {
+	if src == nil {
+		return nil
+	}
+	return saveGob(FnPerformance(epoch), src)
+}
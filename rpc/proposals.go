@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// FnProposals is the outcomes of the proposer duties at the slots of the epoch
+func FnProposals(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.proposals.gz", epoch))
+}
+
+func HasProposals(epoch uint64) bool {
+	return hasFile(FnProposals(epoch))
+}
+
+func LoadProposals(epoch uint64) (*types.EpochProposals, error) {
+	var out types.EpochProposals
+	if err := loadGob(FnProposals(epoch), &out); err != nil {
+		return nil, cacheError(err, "proposals", epoch)
+	}
+	return &out, nil
+}
+
+func SaveProposals(epoch uint64, src *types.EpochProposals) error {
+	if src == nil {
+		return nil
+	}
+	return saveGob(FnProposals(epoch), src)
+}
+
+// reachedSlot returns the head slot of the source, the slots after it are not reached by the node yet.
+// The cache source without the head snapshots has only the blocks of the finished epochs
+func reachedSlot(source BeaconSource) (uint64, error) {
+	head, err := source.GetChainHead()
+	if errors.Is(err, ErrNotCached) {
+		return math.MaxUint64, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving chain head: %w", err)
+	}
+	return head.HeadSlot, nil
+}
+
+// slotBlocks groups the blocks of the epoch by the slot and the hex root,
+// the slots without blocks get a block of the assigned proposer,
+// missed (status 2) up to the head slot of the source or scheduled (status 0) after it
+func slotBlocks(source BeaconSource, assignments *types.Assignments) (map[uint64]map[string]*types.Block, error) {
+	headSlot, err := reachedSlot(source)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := source.GetEpochBlocks(uint64(assignments.Epoch))
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uint64]map[string]*types.Block)
+	for _, block := range blocks {
+		if res[block.Slot] == nil {
+			res[block.Slot] = make(map[string]*types.Block)
+		}
+		res[block.Slot][fmt.Sprintf("%x", block.BlockRoot)] = block
+	}
+
+	// Fill up missed and scheduled blocks
+	for slotIndex, a := range assignments.Assignments {
+		slot := assignments.FirstSlot + uint64(slotIndex)
+		if _, found := res[slot]; found {
+			continue
+		}
+		// Proposer was assigned but did not yet propose a block
+		block := &types.Block{
+			Status:            types.BlockScheduled,
+			Proposer:          a.Proposer,
+			BlockRoot:         []byte{0x0},
+			Slot:              slot,
+			ParentRoot:        []byte{},
+			StateRoot:         []byte{},
+			Signature:         []byte{},
+			RandaoReveal:      []byte{},
+			Graffiti:          []byte{},
+			BodyRoot:          []byte{},
+			Eth1Data:          &types.Eth1Data{},
+			ProposerSlashings: make([]*types.ProposerSlashing, 0),
+			AttesterSlashings: make([]*types.AttesterSlashing, 0),
+			Attestations:      make([]*types.Attestation, 0),
+			Deposits:          make([]*types.Deposit, 0),
+			VoluntaryExits:    make([]*types.VoluntaryExit, 0),
+		}
+		if slot <= headSlot {
+			// Block is behind the head of the node, set status to missed
+			block.Status = types.BlockMissed
+			block.BlockRoot = []byte{0x1}
+		}
+		res[slot] = map[string]*types.Block{"0x0": block}
+	}
+	return res, nil
+}
+
+// EpochProposals derives the outcomes of the proposer duties of the epoch from the assignments and the blocks
+func EpochProposals(source BeaconSource, epoch uint64) (*types.EpochProposals, error) {
+	assignments, err := source.GetEpochAssignments(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving assignments for epoch %v: %w", epoch, err)
+	}
+	blocks, err := slotBlocks(source, assignments)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewEpochProposals(epoch, assignments, blocks), nil
+}
+
+// GetProposals returns the outcomes of the proposer duties of the epochs from-to inclusive,
+// the cached ones are used unless provisional
+func GetProposals(source BeaconSource, from, to uint64) ([]*types.EpochProposals, error) {
+	res := make([]*types.EpochProposals, 0, to-from+1)
+	for epoch := from; epoch <= to; epoch++ {
+		if HasProposals(epoch) && !IsProvisional(FnProposals(epoch)) {
+			proposals, err := LoadProposals(epoch)
+			if err == nil {
+				res = append(res, proposals)
+				continue
+			}
+			logger.Errorf("LoadProposals failure: %v", err)
+		}
+		proposals, err := EpochProposals(source, epoch)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, proposals)
+	}
+	return res, nil
+}
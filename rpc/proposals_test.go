@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"testing"
+)
+
+func TestEpochProposalsAtHead(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 500, Epochs: 2, MissedSlots: []uint64{66}})
+	node.Advance(10)
+
+	// the head slot is 73, the wall clock is far beyond the slots of the fake chain
+	proposals, err := EpochProposals(client, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range proposals.Slots {
+		expected := types.ProposalProposed
+		switch {
+		case p.Slot == 66:
+			expected = types.ProposalMissed
+		case p.Slot > node.Chain.HeadSlot():
+			expected = types.ProposalScheduled
+		}
+		if p.Status != expected {
+			t.Errorf("slot %d has status %v, expected %v", p.Slot, p.Status, expected)
+		}
+	}
+	if proposals.Finished() {
+		t.Error("proposals of the head epoch are finished")
+	}
+}
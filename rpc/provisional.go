@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logprovisional = logrus.New().WithField("module", "provisional")
+
+// FnProvisional returns the marker of the cache file, which was saved before its epoch was finalized
+func FnProvisional(fn string) string {
+	return fn + ".provisional"
+}
+
+// IsProvisional tells whether the cache file can still change, as its epoch was not finalized when it was saved
+func IsProvisional(fn string) bool {
+	_, err := os.Stat(FnProvisional(fn))
+	return err == nil
+}
+
+// MarkFinality marks the cache file of the epoch as provisional until the epoch is finalized,
+// the file saved after the finalization is sealed
+func MarkFinality(fn string, epoch uint64, finalizedEpoch uint64) error {
+	if epoch > finalizedEpoch {
+		return ioutil.WriteFile(FnProvisional(fn), []byte{}, 0644)
+	}
+	if err := os.Remove(FnProvisional(fn)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ProvisionalEpochs returns sorted epochs, which have provisional cache files
+func ProvisionalEpochs() ([]uint64, error) {
+	markers, err := filepath.Glob(CachePath("*.provisional"))
+	if err != nil {
+		return nil, err
+	}
+	unique := make(map[uint64]bool)
+	for _, marker := range markers {
+		prefix := strings.SplitN(filepath.Base(marker), ".", 2)[0]
+		epoch, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		unique[epoch] = true
+	}
+	res := make([]uint64, 0, len(unique))
+	for epoch := range unique {
+		res = append(res, epoch)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+// headTTL is the time the received chain head is used for, without requesting it again
+const headTTL = types.SlotsPerEpoch * types.SecondsPerSlot * time.Second
+
+// finality decides whether the cache files can be read and marks the saved ones as provisional or sealed
+type finality struct {
+	rejectProvisional bool
+	chainHead         func() (*types.ChainHead, error)
+	// head is the last received chain head, refreshed by every request of the head
+	head    *types.ChainHead
+	headAt  time.Time
+	headMux sync.Mutex
+}
+
+// seen remembers the chain head just received from the node
+func (f *finality) seen(head *types.ChainHead) {
+	f.headMux.Lock()
+	defer f.headMux.Unlock()
+	f.head, f.headAt = head, time.Now()
+}
+
+// lastHead returns the last received chain head, so the cache files of the epoch
+// do not request the head each, it is requested again once it is older than the epoch
+func (f *finality) lastHead() (*types.ChainHead, error) {
+	f.headMux.Lock()
+	head, at := f.head, f.headAt
+	f.headMux.Unlock()
+	if head != nil && time.Since(at) < headTTL {
+		return head, nil
+	}
+	return f.chainHead()
+}
+
+// SetAcceptProvisional tells whether data cached before the finalization of its epoch is accepted,
+// otherwise it is fetched again. Provisional data is accepted by default
+func (f *finality) SetAcceptProvisional(accept bool) {
+	f.rejectProvisional = !accept
+}
+
+// usable tells whether the cache file could be read
+func (f *finality) usable(fn string) bool {
+	return !f.rejectProvisional || !IsProvisional(fn)
+}
+
+// finished tells whether all slots of the epoch are behind the chain head
+func (f *finality) finished(epoch uint64) bool {
+	head, err := f.lastHead()
+	if err != nil {
+		logprovisional.Errorf("cannot check the head: %v", err)
+		return false
+	}
+	return head.HeadSlot >= (epoch+1)*types.SlotsPerEpoch-1
+}
+
+// saved marks the just saved cache file of the epoch according to the current finalized epoch
+func (f *finality) saved(fn string, epoch uint64) {
+	if _, err := os.Stat(fn); err != nil {
+		return
+	}
+	head, err := f.lastHead()
+	if err != nil {
+		// without the head nothing but genesis is known to be finalized
+		logprovisional.Errorf("cannot check finalization of %v: %v", fn, err)
+		head = &types.ChainHead{}
+	}
+	if err := MarkFinality(fn, epoch, head.FinalizedEpoch); err != nil {
+		logprovisional.Errorf("cannot mark finalization of %v: %v", fn, err)
+	}
+}
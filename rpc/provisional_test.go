@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"reflect"
+	"testing"
+)
+
+func TestProvisionalAssignments(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4})
+
+	for _, epoch := range []uint64{1, 3} {
+		if _, err := client.GetEpochAssignments(epoch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if IsProvisional(FnAssignments(1)) || !IsProvisional(FnAssignments(3)) {
+		t.Fatal("only the epoch after the finalized one must be provisional")
+	}
+	if epochs, _ := ProvisionalEpochs(); !reflect.DeepEqual(epochs, []uint64{3}) {
+		t.Errorf("unexpected provisional epochs %v", epochs)
+	}
+
+	// epoch 3 is finalized now, the cacher requests the head once per epoch
+	node.Advance(2 * types.SlotsPerEpoch)
+	if _, err := client.GetChainHead(); err != nil {
+		t.Fatal(err)
+	}
+	calls := node.Calls("ListValidatorAssignments")
+	if _, err := client.GetEpochAssignments(3); err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListValidatorAssignments") != calls {
+		t.Error("provisional data is accepted by default")
+	}
+
+	client.SetAcceptProvisional(false)
+	if _, err := client.GetEpochAssignments(3); err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("ListValidatorAssignments") == calls {
+		t.Error("provisional data is not fetched again")
+	}
+	if IsProvisional(FnAssignments(3)) {
+		t.Error("finalized epoch is not sealed")
+	}
+	if epochs, _ := ProvisionalEpochs(); len(epochs) != 0 {
+		t.Errorf("unexpected provisional epochs %v", epochs)
+	}
+}
+
+func TestProvisionalHeadRequestedOnce(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 6})
+
+	if _, err := client.GetChainHead(); err != nil {
+		t.Fatal(err)
+	}
+	calls := node.Calls("GetChainHead")
+	if _, err := client.GetEpochAssignments(4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetEpochValidators(4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetEpochBlocks(4); err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls("GetChainHead") != calls {
+		t.Errorf("head is requested %d times for the cache files", node.Calls("GetChainHead")-calls)
+	}
+	if !HasBlocks(4) || !IsProvisional(FnValidators(4)) {
+		t.Error("cache files are not marked with the received head")
+	}
+}
@@ -0,0 +1,180 @@
+// Package recording captures responses of a beacon node into fixture files
+// and serves them back, so a misbehaving node can be reproduced without it.
+package recording
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var logger = logrus.New().WithField("module", "recording")
+
+// Methods are the BeaconChain and Node methods which responses are recorded
+var Methods = map[string]bool{
+	"GetGenesis":                true,
+	"ListValidatorAssignments":  true,
+	"ListBeaconCommittees":      true,
+	"ListValidators":            true,
+	"ListValidatorBalances":     true,
+	"ListBlocks":                true,
+	"GetValidatorParticipation": true,
+	"GetChainHead":              true,
+}
+
+// FixtureName returns file name of the response to the request of the method,
+// it is unique for every query filter and page token
+func FixtureName(method string, req proto.Message) (string, error) {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal %v request: %w", method, err)
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s-%x.pb", method, sum[:8]), nil
+}
+
+// NewRecorder returns client interceptor, which saves responses of the recorded methods into the folder
+func NewRecorder(dir string) (grpc.UnaryClientInterceptor, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		name := path.Base(method)
+		if err != nil || !Methods[name] {
+			return err
+		}
+		if saveErr := save(dir, name, req.(proto.Message), reply.(proto.Message)); saveErr != nil {
+			logger.Errorf("recording of %v failed: %v", name, saveErr)
+		}
+		return nil
+	}, nil
+}
+
+func save(dir string, method string, req, reply proto.Message) error {
+	name, err := FixtureName(method, req)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(reply)
+	if err != nil {
+		return fmt.Errorf("cannot marshal %v response: %w", method, err)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+}
+
+// ReplayServer serves the responses from the folder, recorded by NewRecorder,
+// as both BeaconChain and Node servers
+type ReplayServer struct {
+	ethpb.UnimplementedBeaconChainServer
+	ethpb.UnimplementedNodeServer
+	dir string
+}
+
+// NewReplayServer returns the server of the recorded responses
+func NewReplayServer(dir string) *ReplayServer {
+	return &ReplayServer{dir: dir}
+}
+
+// load reads the recorded response to the request into reply
+func (s *ReplayServer) load(method string, req, reply proto.Message) error {
+	name, err := FixtureName(method, req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return status.Errorf(codes.NotFound, "no recorded response %v to %v", name, req)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if err := proto.Unmarshal(data, reply); err != nil {
+		return status.Errorf(codes.DataLoss, "corrupted fixture %v: %v", name, err)
+	}
+	return nil
+}
+
+// ListValidatorAssignments replays the recorded response
+func (s *ReplayServer) ListValidatorAssignments(ctx context.Context, req *ethpb.ListValidatorAssignmentsRequest) (*ethpb.ValidatorAssignments, error) {
+	var reply ethpb.ValidatorAssignments
+	if err := s.load("ListValidatorAssignments", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListValidators replays the recorded response
+func (s *ReplayServer) ListValidators(ctx context.Context, req *ethpb.ListValidatorsRequest) (*ethpb.Validators, error) {
+	var reply ethpb.Validators
+	if err := s.load("ListValidators", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListValidatorBalances replays the recorded response
+func (s *ReplayServer) ListValidatorBalances(ctx context.Context, req *ethpb.ListValidatorBalancesRequest) (*ethpb.ValidatorBalances, error) {
+	var reply ethpb.ValidatorBalances
+	if err := s.load("ListValidatorBalances", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// GetValidatorParticipation replays the recorded response
+func (s *ReplayServer) GetValidatorParticipation(ctx context.Context, req *ethpb.GetValidatorParticipationRequest) (*ethpb.ValidatorParticipationResponse, error) {
+	var reply ethpb.ValidatorParticipationResponse
+	if err := s.load("GetValidatorParticipation", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// GetChainHead replays the last recorded head
+func (s *ReplayServer) GetChainHead(ctx context.Context, req *empty.Empty) (*ethpb.ChainHead, error) {
+	var reply ethpb.ChainHead
+	if err := s.load("GetChainHead", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListBeaconCommittees replays the recorded response
+func (s *ReplayServer) ListBeaconCommittees(ctx context.Context, req *ethpb.ListCommitteesRequest) (*ethpb.BeaconCommittees, error) {
+	var reply ethpb.BeaconCommittees
+	if err := s.load("ListBeaconCommittees", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListBlocks replays the recorded response
+func (s *ReplayServer) ListBlocks(ctx context.Context, req *ethpb.ListBlocksRequest) (*ethpb.ListBlocksResponse, error) {
+	var reply ethpb.ListBlocksResponse
+	if err := s.load("ListBlocks", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// GetGenesis replays the recorded genesis of the node
+func (s *ReplayServer) GetGenesis(ctx context.Context, req *empty.Empty) (*ethpb.Genesis, error) {
+	var reply ethpb.Genesis
+	if err := s.load("GetGenesis", req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
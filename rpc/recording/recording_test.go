@@ -0,0 +1,100 @@
+package recording_test
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"beaconchain/rpc/recording"
+	"beaconchain/rpc/rpctest"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startReplay serves the recorded responses over the in-process connection
+func startReplay(t *testing.T, dir string) *rpc.PrysmClient {
+	listener := bufconn.Listen(16 * 1024 * 1024)
+	server := grpc.NewServer()
+	replay := recording.NewReplayServer(dir)
+	ethpb.RegisterBeaconChainServer(server, replay)
+	ethpb.RegisterNodeServer(server, replay)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	})
+	client, err := rpc.NewPrysmClient("bufnet", dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	fixtures := t.TempDir()
+	node := rpctest.NewTestNode(t, fakenode.Config{
+		Validators:    1000,
+		Epochs:        3,
+		PageSize:      300,
+		MissedSlots:   []uint64{35},
+		OrphanedSlots: []uint64{40},
+		GenesisTime:   1606824023,
+	})
+
+	recorder, err := recording.NewRecorder(fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := rpc.NewPrysmClient(fakenode.Endpoint, node.DialOption(), grpc.WithUnaryInterceptor(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+
+	rpc.SetCacheDir(t.TempDir())
+	recorded, err := live.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.GetGenesisTimestamp(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the replayed node must produce exactly the same data without the cache
+	rpc.SetCacheDir(t.TempDir())
+	client := startReplay(t, fixtures)
+	replayed, err := client.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genesis, err := client.GetGenesisTimestamp(); err != nil || genesis != 1606824023 {
+		t.Errorf("replayed genesis %v: %v", genesis, err)
+	}
+	if !reflect.DeepEqual(recorded.ValidatorAssignments, replayed.ValidatorAssignments) {
+		t.Error("replayed assignments mismatch")
+	}
+	if !reflect.DeepEqual(recorded.Validators, replayed.Validators) {
+		t.Error("replayed validators mismatch")
+	}
+	if !reflect.DeepEqual(recorded.Blocks, replayed.Blocks) {
+		t.Error("replayed blocks mismatch")
+	}
+	if !reflect.DeepEqual(recorded.EpochParticipationStats, replayed.EpochParticipationStats) {
+		t.Error("replayed participation mismatch")
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	rpc.SetCacheDir(t.TempDir())
+	client := startReplay(t, t.TempDir())
+
+	if _, err := client.GetBlocksBySlot(10); err == nil {
+		t.Error("expected error for the request which was not recorded")
+	}
+}
@@ -0,0 +1,249 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"bytes"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logreorg = logrus.New().WithField("module", "reorg")
+
+// ReorgSource provides the blocks of the node and drops the data derived from replaced blocks
+type ReorgSource interface {
+	// GetChainHead returns the current head of the node
+	GetChainHead() (*types.ChainHead, error)
+	// GetMinimalBlocksByEpoch returns all blocks of the epoch known to the node, canonical or not
+	GetMinimalBlocksByEpoch(epoch uint64) ([]*types.MinimalBlock, error)
+	// InvalidateEpoch drops the cached data of the epoch
+	InvalidateEpoch(epoch uint64) error
+}
+
+var _ ReorgSource = (*PrysmClient)(nil)
+
+// ReorgDetector tracks canonical block roots of every slot of the seen epochs
+// and reports when the node changes them. Epochs, which are not tracked yet,
+// are compared with their cached blocks
+type ReorgDetector struct {
+	source ReorgSource
+	// roots of the canonical blocks by slot, empty root for the missed slot,
+	// slots ahead of the head without a block are not tracked
+	roots map[uint64][]byte
+	// epochs which slots are tracked
+	epochs map[uint64]bool
+	mux    sync.Mutex
+}
+
+// NewReorgDetector returns the detector, which compares tracked blocks with the source
+func NewReorgDetector(source ReorgSource) *ReorgDetector {
+	return &ReorgDetector{
+		source: source,
+		roots:  make(map[uint64][]byte),
+		epochs: make(map[uint64]bool),
+	}
+}
+
+// canonicalRoots returns roots of the canonical blocks for every slot of the epoch
+func canonicalRoots(epoch uint64, blocks []*types.MinimalBlock) map[uint64][]byte {
+	res := make(map[uint64][]byte, types.SlotsPerEpoch)
+	for slot := epoch * types.SlotsPerEpoch; slot < (epoch+1)*types.SlotsPerEpoch; slot++ {
+		res[slot] = []byte{}
+	}
+	for _, block := range blocks {
+		if _, ok := res[block.Slot]; ok && block.Canonical {
+			res[block.Slot] = block.BlockRoot
+		}
+	}
+	return res
+}
+
+// Track remembers the blocks of the epoch, seen at the head slot, as the known canonical chain,
+// slots up to the head without a canonical block are considered as missed
+func (d *ReorgDetector) Track(epoch uint64, headSlot uint64, blocks []*types.MinimalBlock) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.track(epoch, headSlot, blocks)
+}
+
+func (d *ReorgDetector) track(epoch uint64, headSlot uint64, blocks []*types.MinimalBlock) {
+	for slot, root := range canonicalRoots(epoch, blocks) {
+		if len(root) == 0 && slot > headSlot {
+			// the block of the slot can still be proposed
+			delete(d.roots, slot)
+			continue
+		}
+		d.roots[slot] = root
+	}
+	d.epochs[epoch] = true
+}
+
+// trackCached starts tracking the epoch from its cached blocks, which are saved once the epoch is finished.
+// Returns false when the blocks of the epoch are not cached
+func (d *ReorgDetector) trackCached(epoch uint64) bool {
+	if !HasBlocks(epoch) {
+		return false
+	}
+	blocks, err := LoadBlocks(epoch)
+	if err != nil {
+		logreorg.Errorf("LoadBlocks failure: %v", err)
+		return false
+	}
+	minimal := make([]*types.MinimalBlock, 0, len(blocks))
+	for _, b := range blocks {
+		minimal = append(minimal, &types.MinimalBlock{
+			Epoch:      epoch,
+			Slot:       b.Slot,
+			BlockRoot:  b.BlockRoot,
+			ParentRoot: b.ParentRoot,
+			Canonical:  b.Canonical,
+		})
+	}
+	d.track(epoch, (epoch+1)*types.SlotsPerEpoch-1, minimal)
+	return true
+}
+
+// Compare returns the slots of the tracked or cached epoch, which canonical block differs on the node,
+// nothing is returned for the epoch which is neither tracked nor cached
+func (d *ReorgDetector) Compare(epoch uint64) ([]*types.BlockComparisonContainer, error) {
+	blocks, err := d.source.GetMinimalBlocksByEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if !d.epochs[epoch] {
+		d.trackCached(epoch)
+	}
+	return d.compare(epoch, blocks), nil
+}
+
+func (d *ReorgDetector) compare(epoch uint64, blocks []*types.MinimalBlock) []*types.BlockComparisonContainer {
+	res := make([]*types.BlockComparisonContainer, 0)
+	if !d.epochs[epoch] {
+		return res
+	}
+	byRoot := make(map[string]*types.MinimalBlock, len(blocks))
+	for _, block := range blocks {
+		byRoot[string(block.BlockRoot)] = block
+	}
+	node := canonicalRoots(epoch, blocks)
+	for slot := epoch * types.SlotsPerEpoch; slot < (epoch+1)*types.SlotsPerEpoch; slot++ {
+		tracked, ok := d.roots[slot]
+		if !ok || bytes.Equal(tracked, node[slot]) {
+			continue
+		}
+		c := &types.BlockComparisonContainer{Epoch: epoch}
+		if len(d.roots[slot]) > 0 {
+			c.Db = &types.MinimalBlock{Epoch: epoch, Slot: slot, BlockRoot: d.roots[slot], Canonical: true}
+		}
+		if len(node[slot]) > 0 {
+			c.Node = byRoot[string(node[slot])]
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+func comparedSlot(c *types.BlockComparisonContainer) uint64 {
+	if c.Db != nil {
+		return c.Db.Slot
+	}
+	return c.Node.Slot
+}
+
+func minimalRoot(b *types.MinimalBlock) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b.BlockRoot
+}
+
+// ancestorSlot returns the slot of the tracked block, from which the node's chain forks at the first compared slot.
+// The fork is found by the parent of the first new block, the slot before the comparison is returned without it
+func (d *ReorgDetector) ancestorSlot(diff []*types.BlockComparisonContainer) uint64 {
+	first := comparedSlot(diff[0])
+	for _, c := range diff {
+		if c.Node == nil {
+			continue
+		}
+		for slot, root := range d.roots {
+			if slot < c.Node.Slot && len(root) > 0 && bytes.Equal(root, c.Node.ParentRoot) {
+				return slot
+			}
+		}
+		break
+	}
+	if first == 0 {
+		return 0
+	}
+	return first - 1
+}
+
+// Detect compares the epoch with the node and returns the reorg, if canonical blocks were replaced.
+// A reorg is followed through the next tracked or cached epochs, so it is reported once with all replaced slots.
+// The node's view becomes the tracked one and the cached data of the reorged epochs and the next one is invalidated.
+// The first call for the epoch which is neither tracked nor cached only starts tracking it
+func (d *ReorgDetector) Detect(epoch uint64) (*types.ReorgEvent, error) {
+	head, err := d.source.GetChainHead()
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := d.source.GetMinimalBlocksByEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.epochs[epoch] {
+		d.trackCached(epoch)
+	}
+	diff := d.compare(epoch, blocks)
+	if len(diff) == 0 {
+		d.track(epoch, head.HeadSlot, blocks)
+		return nil, nil
+	}
+	// the ancestor is looked up before the node's view replaces the tracked roots
+	ancestor := d.ancestorSlot(diff)
+	d.track(epoch, head.HeadSlot, blocks)
+
+	last := epoch
+	for next := epoch + 1; next <= head.HeadEpoch; next++ {
+		if !d.epochs[next] && !d.trackCached(next) {
+			break
+		}
+		blocks, err := d.source.GetMinimalBlocksByEpoch(next)
+		if err != nil {
+			return nil, err
+		}
+		nextDiff := d.compare(next, blocks)
+		d.track(next, head.HeadSlot, blocks)
+		if len(nextDiff) == 0 {
+			break
+		}
+		diff = append(diff, nextDiff...)
+		last = next
+	}
+
+	event := &types.ReorgEvent{
+		Epoch:    epoch,
+		Slot:     comparedSlot(diff[0]),
+		Depth:    comparedSlot(diff[len(diff)-1]) - ancestor,
+		OldRoots: make([][]byte, 0, len(diff)),
+		NewRoots: make([][]byte, 0, len(diff)),
+	}
+	for _, c := range diff {
+		event.OldRoots = append(event.OldRoots, minimalRoot(c.Db))
+		event.NewRoots = append(event.NewRoots, minimalRoot(c.Node))
+	}
+	logreorg.Warnf("reorg of %d slots at epochs %d-%d since slot %d", event.Depth, epoch, last, event.Slot)
+
+	// state of the next epoch is derived from the blocks of the reorged ones
+	for e := epoch; e <= last+1; e++ {
+		if err := d.source.InvalidateEpoch(e); err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
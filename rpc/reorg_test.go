@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"bytes"
+	"testing"
+)
+
+func TestReorgDetector(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4})
+	if _, err := client.GetEpochValidators(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetEpochAssignments(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if !HasValidators(2) || !HasAssignments(3) {
+		t.Fatal("epochs are not cached")
+	}
+
+	detector := NewReorgDetector(client)
+	stored, err := client.GetMinimalBlocksByEpoch(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	detector.Track(2, node.Chain.HeadSlot(), stored)
+	if event, err := detector.Detect(2); err != nil || event != nil {
+		t.Fatalf("unexpected reorg %+v, %v", event, err)
+	}
+
+	oldRoot := node.Chain.CanonicalRoot(70)
+	node.Reorg(70)
+	diff, err := detector.Compare(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 26 || diff[0].Db.Slot != 70 || diff[0].Node.Slot != 70 || !bytes.Equal(diff[0].Db.BlockRoot, oldRoot) {
+		t.Fatalf("unexpected comparison of %d slots", len(diff))
+	}
+
+	event, err := detector.Detect(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event == nil || event.Slot != 70 || event.Depth != 26 {
+		t.Fatalf("unexpected reorg %+v", event)
+	}
+	if !bytes.Equal(event.OldRoots[0], oldRoot) || !bytes.Equal(event.NewRoots[0], node.Chain.CanonicalRoot(70)) {
+		t.Error("unexpected roots of the reorg")
+	}
+	if HasValidators(2) || HasBalances(2) || HasAssignments(3) {
+		t.Error("cached data of the reorged epochs is not invalidated")
+	}
+
+	if event, err := detector.Detect(2); err != nil || event != nil {
+		t.Errorf("reorg is reported twice %+v, %v", event, err)
+	}
+}
+
+func TestReorgDetectorSkipsSlotsAheadOfHead(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 3})
+	node.Advance(10)
+
+	detector := NewReorgDetector(client)
+	if event, err := detector.Detect(3); err != nil || event != nil {
+		t.Fatalf("unexpected reorg %+v, %v", event, err)
+	}
+	// the blocks of the slots, which were ahead of the head, are not a reorg
+	node.Advance(types.SlotsPerEpoch - 10)
+	if event, err := detector.Detect(3); err != nil || event != nil {
+		t.Fatalf("proposed blocks are reported as reorg %+v, %v", event, err)
+	}
+
+	node.Reorg(120)
+	event, err := detector.Detect(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event == nil || event.Slot != 120 || event.Depth != 8 {
+		t.Fatalf("unexpected reorg %+v", event)
+	}
+}
+
+func TestReorgDetectorComparesCachedBlocks(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4})
+	if _, err := client.GetEpochBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+	if !HasBlocks(2) {
+		t.Fatal("blocks are not cached")
+	}
+
+	oldRoot := node.Chain.CanonicalRoot(70)
+	node.Reorg(70)
+	// the detector of the next run knows the epoch from its cached blocks only
+	event, err := NewReorgDetector(client).Detect(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event == nil || event.Slot != 70 || event.Depth != 26 || !bytes.Equal(event.OldRoots[0], oldRoot) {
+		t.Fatalf("unexpected reorg %+v", event)
+	}
+	if HasBlocks(2) {
+		t.Error("cached blocks of the reorged epoch are not invalidated")
+	}
+}
+
+func TestReorgDetectorAcrossEpochs(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 4, MissedSlots: []uint64{88, 89}})
+	if _, err := client.GetEpochValidators(3); err != nil {
+		t.Fatal(err)
+	}
+
+	detector := NewReorgDetector(client)
+	for _, epoch := range []uint64{2, 3} {
+		if event, err := detector.Detect(epoch); err != nil || event != nil {
+			t.Fatalf("unexpected reorg %+v, %v", event, err)
+		}
+	}
+
+	// the common ancestor is the block of the slot 87, the slots 88 and 89 are missed on both chains
+	node.Reorg(88)
+	event, err := detector.Detect(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event == nil || event.Epoch != 2 || event.Slot != 90 || event.Depth != 127-87 || len(event.OldRoots) != 127-89 {
+		t.Fatalf("unexpected reorg %+v", event)
+	}
+	if HasValidators(3) {
+		t.Error("cached data of the next reorged epoch is not invalidated")
+	}
+	if event, err := detector.Detect(3); err != nil || event != nil {
+		t.Errorf("reorg is reported twice %+v, %v", event, err)
+	}
+}
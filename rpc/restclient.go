@@ -0,0 +1,484 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+)
+
+// RestClient holds information about the connection to the standard Beacon Node API,
+// served by Lighthouse, Teku, Nimbus and Prysm gateway
+type RestClient struct {
+	endpoint         string
+	httpClient       *http.Client
+	assignmentsCache *lru.Cache
+	flights          singleflight.Group
+	parallelism      int
+	finality
+	lookbacks
+}
+
+// NewRestClient is used for a new client of the standard Beacon Node API
+func NewRestClient(endpoint string) (*RestClient, error) {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "http://" + endpoint
+	}
+	client := &RestClient{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		httpClient:  &http.Client{Timeout: 5 * time.Minute},
+		parallelism: cfgParallelism,
+	}
+	client.chainHead = client.GetChainHead
+	client.assignmentsCache, _ = lru.New(10)
+	return client, nil
+}
+
+// Close will close idle connections of the client
+func (rc *RestClient) Close() {
+	rc.httpClient.CloseIdleConnections()
+}
+
+// SetParallelism limits the number of concurrent requests to the node while assembling an epoch
+func (rc *RestClient) SetParallelism(limit int) {
+	rc.parallelism = limit
+}
+
+// get requests the path and decodes the "data" field of the response into out
+func (rc *RestClient) get(path string, out interface{}) error {
+	resp, err := rc.httpClient.Get(rc.endpoint + path)
+	if err != nil {
+		return &NodeError{Method: "GET " + path, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return &NodeError{Method: "GET " + path, Err: ErrNotFound}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &NodeError{Method: "GET " + path, Err: &httpStatusError{Code: resp.StatusCode, Status: resp.Status}}
+	}
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("GET %v: %w", path, err)
+	}
+	return nil
+}
+
+// stateID returns identifier of the state at the start of the epoch
+func stateID(epoch uint64) string {
+	if epoch == 0 {
+		return "genesis"
+	}
+	return fmt.Sprintf("%d", epoch*types.SlotsPerEpoch)
+}
+
+// GetGenesisTimestamp returns the genesis timestamp of the beacon chain
+func (rc *RestClient) GetGenesisTimestamp() (int64, error) {
+	var genesis restGenesis
+	if err := rc.get("/eth/v1/beacon/genesis", &genesis); err != nil {
+		return 0, err
+	}
+	return int64(genesis.GenesisTime), nil
+}
+
+// GetChainHead will get the chain head from the node
+func (rc *RestClient) GetChainHead() (*types.ChainHead, error) {
+	var head restHeader
+	if err := rc.get("/eth/v1/beacon/headers/head", &head); err != nil {
+		return nil, err
+	}
+	var checkpoints restFinalityCheckpoints
+	if err := rc.get("/eth/v1/beacon/states/head/finality_checkpoints", &checkpoints); err != nil {
+		return nil, err
+	}
+	headSlot := uint64(head.Header.Message.Slot)
+	res := &types.ChainHead{
+		HeadSlot:                   headSlot,
+		HeadEpoch:                  EpochOfSlot(headSlot),
+		HeadBlockRoot:              head.Root,
+		FinalizedSlot:              uint64(checkpoints.Finalized.Epoch) * types.SlotsPerEpoch,
+		FinalizedEpoch:             uint64(checkpoints.Finalized.Epoch),
+		FinalizedBlockRoot:         checkpoints.Finalized.Root,
+		JustifiedSlot:              uint64(checkpoints.CurrentJustified.Epoch) * types.SlotsPerEpoch,
+		JustifiedEpoch:             uint64(checkpoints.CurrentJustified.Epoch),
+		JustifiedBlockRoot:         checkpoints.CurrentJustified.Root,
+		PreviousJustifiedSlot:      uint64(checkpoints.PreviousJustified.Epoch) * types.SlotsPerEpoch,
+		PreviousJustifiedEpoch:     uint64(checkpoints.PreviousJustified.Epoch),
+		PreviousJustifiedBlockRoot: checkpoints.PreviousJustified.Root,
+	}
+	rc.seen(res)
+	return res, nil
+}
+
+// GetEpochAssignments will get the epoch proposers and committees from the node,
+// concurrent calls for the same epoch share a single fetch
+func (rc *RestClient) GetEpochAssignments(epoch uint64) (*types.Assignments, error) {
+	cachedValue, found := rc.assignmentsCache.Get(epoch)
+	if found && rc.usable(FnAssignments(epoch)) {
+		return cachedValue.(*types.Assignments), nil
+	}
+	v, err, _ := rc.flights.Do(fmt.Sprintf("assignments-%d", epoch), func() (interface{}, error) {
+		return rc.fetchEpochAssignments(epoch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.Assignments), nil
+}
+
+func (rc *RestClient) fetchEpochAssignments(epoch uint64) (*types.Assignments, error) {
+	cachedValue, found := rc.assignmentsCache.Get(epoch)
+	if found && rc.usable(FnAssignments(epoch)) {
+		return cachedValue.(*types.Assignments), nil
+	}
+	if HasAssignments(epoch) && rc.usable(FnAssignments(epoch)) {
+		out, err := LoadAssignments(epoch)
+		if err == nil {
+			rc.assignmentsCache.Add(epoch, out)
+			return out, nil
+		}
+		logger.Errorf("LoadAssignments failure: %v", err)
+	}
+
+	start := time.Now()
+	proposers, err := rc.GetProposerDuties(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving proposer duties: %w", err)
+	}
+
+	var list []restCommittee
+	path := fmt.Sprintf("/eth/v1/beacon/states/%s/committees?epoch=%d", stateID(epoch), epoch)
+	if err := rc.get(path, &list); err != nil {
+		return nil, fmt.Errorf("error retrieving committees: %w", err)
+	}
+	committees := make(map[uint64]map[uint64][]uint64)
+	for _, committee := range list {
+		slot := uint64(committee.Slot)
+		if committees[slot] == nil {
+			committees[slot] = make(map[uint64][]uint64)
+		}
+		committees[slot][uint64(committee.Index)] = restIndexes(committee.Validators)
+	}
+
+	out := NewAssignmentsFromCommittees(epoch, proposers, committees)
+	if len(out.Assignments) > 0 {
+		SaveAssignments(epoch, out)
+		rc.saved(FnAssignments(epoch), epoch)
+		rc.assignmentsCache.Add(epoch, out)
+	}
+	logger.Infof("assignments for epoch %v took %v", epoch, time.Since(start))
+	return out, nil
+}
+
+// GetProposerDuties returns the proposer index by slot of the epoch
+func (rc *RestClient) GetProposerDuties(epoch uint64) (map[uint64]uint64, error) {
+	var duties []restProposerDuty
+	if err := rc.get(fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch), &duties); err != nil {
+		return nil, err
+	}
+	proposers := make(map[uint64]uint64, len(duties))
+	for _, duty := range duties {
+		proposers[uint64(duty.Slot)] = uint64(duty.ValidatorIndex)
+	}
+	return proposers, nil
+}
+
+// GetSeedMix returns the RANDAO mix the seeds of the epoch are derived from, the mix of the epoch
+// MIN_SEED_LOOKAHEAD+1 epochs before it. The first epochs are seeded by the mix of the genesis state
+func (rc *RestClient) GetSeedMix(epoch uint64) ([32]byte, error) {
+	var mix [32]byte
+	path := "/eth/v1/beacon/states/genesis/randao"
+	if epoch >= 2 {
+		path = fmt.Sprintf("/eth/v1/beacon/states/%s/randao?epoch=%d", stateID(epoch), epoch-2)
+	}
+	var randao restRandao
+	if err := rc.get(path, &randao); err != nil {
+		return mix, err
+	}
+	if len(randao.Randao) != len(mix) {
+		return mix, fmt.Errorf("RANDAO mix of %d bytes for epoch %d", len(randao.Randao), epoch)
+	}
+	copy(mix[:], randao.Randao)
+	return mix, nil
+}
+
+// GetBalancesForEpoch returns balances of all validators at the start of the epoch,
+// concurrent calls for the same epoch share a single fetch and the same map, which must not be modified
+func (rc *RestClient) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
+	if epoch < 0 {
+		epoch = 0
+	}
+	v, err, _ := rc.flights.Do(fmt.Sprintf("balances-%d", epoch), func() (interface{}, error) {
+		return rc.fetchBalancesForEpoch(epoch)
+	})
+	balances, _ := v.(map[uint64]uint64)
+	return balances, err
+}
+
+func (rc *RestClient) fetchBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
+	if HasBalances(epoch) && rc.usable(FnBalances(epoch)) {
+		return LoadBalances(epoch)
+	}
+
+	var list []restBalance
+	if err := rc.get(fmt.Sprintf("/eth/v1/beacon/states/%s/validator_balances", stateID(uint64(epoch))), &list); err != nil {
+		return nil, fmt.Errorf("error retrieving validator balances for epoch %v: %w", epoch, err)
+	}
+	validatorBalances := make(map[uint64]uint64, len(list))
+	for _, balance := range list {
+		validatorBalances[uint64(balance.Index)] = uint64(balance.Balance)
+	}
+	SaveBalances(epoch, validatorBalances)
+	rc.saved(FnBalances(epoch), uint64(epoch))
+	return validatorBalances, nil
+}
+
+// GetEpochValidators returns validator set of the epoch, with the balances of the lookback windows,
+// concurrent calls for the same epoch share a single fetch
+func (rc *RestClient) GetEpochValidators(epoch uint64) ([]*types.Validator, error) {
+	v, err, _ := rc.flights.Do(fmt.Sprintf("validators-%d", epoch), func() (interface{}, error) {
+		return rc.fetchEpochValidators(epoch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*types.Validator), nil
+}
+
+func (rc *RestClient) fetchEpochValidators(epoch uint64) ([]*types.Validator, error) {
+	if HasValidators(epoch) && rc.usable(FnValidators(epoch)) {
+		res, err := LoadValidators(epoch)
+		if err == nil {
+			var out []*types.Validator
+			if out, err = withCachedLookback(epoch, res); err == nil {
+				return out, nil
+			}
+		}
+		logger.Errorf("cached validators of epoch %d failure: %v", epoch, err)
+	}
+
+	since := time.Now()
+
+	var list []restValidator
+	if err := rc.get(fmt.Sprintf("/eth/v1/beacon/states/%s/validators", stateID(epoch)), &list); err != nil {
+		return nil, fmt.Errorf("error retrieving validators for epoch %v: %w", epoch, err)
+	}
+	cached := make([]types.ValidatorF, 0, len(list))
+	for _, validator := range list {
+		index := uint64(validator.Index)
+		val := types.ValidatorF{
+			Index:                      index,
+			Balance:                    uint64(validator.Balance),
+			EffectiveBalance:           uint64(validator.Validator.EffectiveBalance),
+			Slashed:                    validator.Validator.Slashed,
+			ActivationEligibilityEpoch: uint64(validator.Validator.ActivationEligibilityEpoch),
+			ActivationEpoch:            uint64(validator.Validator.ActivationEpoch),
+			ExitEpoch:                  uint64(validator.Validator.ExitEpoch),
+			WithdrawableEpoch:          uint64(validator.Validator.WithdrawableEpoch),
+		}
+		copy(val.PublicKey[:], validator.Validator.PublicKey)
+		copy(val.WithdrawalCredentials[:], validator.Validator.WithdrawalCredentials)
+		val.Status = val.StatusAt(epoch)
+		cached = append(cached, val)
+	}
+	lookback, err := rc.balanceLookback(&rc.finality, rc.parallelism, epoch, cached, rc.GetBalancesForEpoch)
+	if err != nil {
+		return nil, err
+	}
+	out := withLookback(cached, lookback)
+	logger.Printf("list of %v validators for epoch %v took %v", len(out), epoch, time.Since(since))
+	SaveValidators(epoch, cached)
+	rc.saved(FnValidators(epoch), epoch)
+	return out, nil
+}
+
+// GetEpochBlocks returns all blocks of the epoch, canonical or not, from the cache or from the node,
+// the epoch is cached once all its slots are behind the head
+func (rc *RestClient) GetEpochBlocks(epoch uint64) ([]*types.Block, error) {
+	v, err, _ := rc.flights.Do(fmt.Sprintf("blocks-%d", epoch), func() (interface{}, error) {
+		if HasBlocks(epoch) && rc.usable(FnBlocks(epoch)) {
+			blocks, err := LoadBlocks(epoch)
+			if err == nil {
+				return blocks, nil
+			}
+			logger.Errorf("LoadBlocks failure: %v", err)
+		}
+		blocks, err := fetchBlocks(rc.parallelism, epoch, rc.GetBlocksBySlot)
+		if err != nil {
+			return nil, err
+		}
+		blocks = retainBlocks(epoch, blocks, FnBlocks(epoch), FnReplacedBlocks(epoch))
+		if rc.finished(epoch) {
+			if err := SaveBlocks(epoch, blocks); err != nil {
+				logger.Errorf("SaveBlocks failure: %v", err)
+			} else {
+				savedBlocks(epoch)
+			}
+			rc.saved(FnBlocks(epoch), epoch)
+		}
+		return blocks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*types.Block), nil
+}
+
+// GetBlocksBySlot will get all blocks of the slot, canonical or not
+func (rc *RestClient) GetBlocksBySlot(slot uint64) ([]*types.Block, error) {
+	blocks := make([]*types.Block, 0)
+
+	var headers []restHeader
+	err := rc.get(fmt.Sprintf("/eth/v1/beacon/headers?slot=%d", slot), &headers)
+	if errors.Is(err, ErrNotFound) {
+		return blocks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range headers {
+		var block restBlock
+		if err := rc.get(fmt.Sprintf("/eth/v1/beacon/blocks/0x%x", []byte(header.Root)), &block); err != nil {
+			return nil, fmt.Errorf("error retrieving block %x: %w", []byte(header.Root), err)
+		}
+		b, err := rc.parseRestBlock(header, &block)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// GetEpochData will get the epoch data from the node, without the participation statistics
+func (rc *RestClient) GetEpochData(epoch uint64) (*types.EpochData, error) {
+	return assembleEpochData(rc, epoch)
+}
+
+// GetValidatorParticipation is not a part of the standard API
+func (rc *RestClient) GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
+	return nil, fmt.Errorf("validator participation for epoch %v: %w", epoch, ErrNotSupported)
+}
+
+func (d *restAttestationData) toAttestationData() *types.AttestationData {
+	return &types.AttestationData{
+		Slot:            uint64(d.Slot),
+		CommitteeIndex:  uint64(d.Index),
+		BeaconBlockRoot: d.BeaconBlockRoot,
+		Source: &types.Checkpoint{
+			Epoch: uint64(d.Source.Epoch),
+			Root:  d.Source.Root,
+		},
+		Target: &types.Checkpoint{
+			Epoch: uint64(d.Target.Epoch),
+			Root:  d.Target.Root,
+		},
+	}
+}
+
+func (h *restSignedBlockHeader) toBlock() *types.Block {
+	return &types.Block{
+		Slot:       uint64(h.Message.Slot),
+		ParentRoot: h.Message.ParentRoot,
+		StateRoot:  h.Message.StateRoot,
+		Signature:  h.Signature,
+		BodyRoot:   h.Message.BodyRoot,
+	}
+}
+
+func (rc *RestClient) parseRestBlock(header restHeader, block *restBlock) (*types.Block, error) {
+	body := &block.Message.Body
+	b := &types.Block{
+		Status:       types.BlockStatus(header.Canonical),
+		Canonical:    header.Canonical,
+		BlockRoot:    header.Root,
+		Slot:         uint64(block.Message.Slot),
+		ParentRoot:   block.Message.ParentRoot,
+		StateRoot:    block.Message.StateRoot,
+		Signature:    block.Signature,
+		RandaoReveal: body.RandaoReveal,
+		Graffiti:     body.Graffiti,
+		BodyRoot:     header.Header.Message.BodyRoot,
+		Eth1Data: &types.Eth1Data{
+			DepositRoot:  body.Eth1Data.DepositRoot,
+			DepositCount: uint64(body.Eth1Data.DepositCount),
+			BlockHash:    body.Eth1Data.BlockHash,
+		},
+		ProposerSlashings: make([]*types.ProposerSlashing, len(body.ProposerSlashings)),
+		AttesterSlashings: make([]*types.AttesterSlashing, len(body.AttesterSlashings)),
+		Attestations:      make([]*types.Attestation, len(body.Attestations)),
+		Deposits:          make([]*types.Deposit, len(body.Deposits)),
+		VoluntaryExits:    make([]*types.VoluntaryExit, len(body.VoluntaryExits)),
+		Proposer:          uint64(block.Message.ProposerIndex),
+	}
+
+	for i, proposerSlashing := range body.ProposerSlashings {
+		b.ProposerSlashings[i] = &types.ProposerSlashing{
+			ProposerIndex: uint64(proposerSlashing.SignedHeader1.Message.ProposerIndex),
+			Header1:       proposerSlashing.SignedHeader1.toBlock(),
+			Header2:       proposerSlashing.SignedHeader2.toBlock(),
+		}
+	}
+
+	for i, attesterSlashing := range body.AttesterSlashings {
+		b.AttesterSlashings[i] = &types.AttesterSlashing{
+			Attestation1: &types.IndexedAttestation{
+				Data:             attesterSlashing.Attestation1.Data.toAttestationData(),
+				Signature:        attesterSlashing.Attestation1.Signature,
+				AttestingIndices: restIndexes(attesterSlashing.Attestation1.AttestingIndices),
+			},
+			Attestation2: &types.IndexedAttestation{
+				Data:             attesterSlashing.Attestation2.Data.toAttestationData(),
+				Signature:        attesterSlashing.Attestation2.Signature,
+				AttestingIndices: restIndexes(attesterSlashing.Attestation2.AttestingIndices),
+			},
+		}
+	}
+
+	for i, attestation := range body.Attestations {
+		a := &types.Attestation{
+			AggregationBits: attestation.AggregationBits,
+			Data:            attestation.Data.toAttestationData(),
+			Signature:       attestation.Signature,
+		}
+		assignments, err := rc.GetEpochAssignments(a.Data.Slot / types.SlotsPerEpoch)
+		if err != nil {
+			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %w",
+				a.Data.Slot/types.SlotsPerEpoch, err)
+		}
+		resolveAttesters(assignments, a, b.Slot)
+		b.Attestations[i] = a
+	}
+
+	for i, deposit := range body.Deposits {
+		proof := make([][]byte, len(deposit.Proof))
+		for k, p := range deposit.Proof {
+			proof[k] = p
+		}
+		b.Deposits[i] = &types.Deposit{
+			Proof:                 proof,
+			PublicKey:             deposit.Data.PublicKey,
+			WithdrawalCredentials: deposit.Data.WithdrawalCredentials,
+			Amount:                uint64(deposit.Data.Amount),
+			Signature:             deposit.Data.Signature,
+		}
+	}
+
+	for i, voluntaryExit := range body.VoluntaryExits {
+		b.VoluntaryExits[i] = &types.VoluntaryExit{
+			Epoch:          uint64(voluntaryExit.Message.Epoch),
+			ValidatorIndex: uint64(voluntaryExit.Message.ValidatorIndex),
+			Signature:      voluntaryExit.Signature,
+		}
+	}
+	return b, nil
+}
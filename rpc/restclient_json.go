@@ -0,0 +1,192 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// restUint64 is a decimal number, encoded as a string by the standard API
+type restUint64 uint64
+
+func (n *restUint64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	*n = restUint64(v)
+	return nil
+}
+
+// restBytes is a 0x-prefixed hex string
+type restBytes []byte
+
+func (b *restBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid hex %q: %w", s, err)
+	}
+	*b = v
+	return nil
+}
+
+func restIndexes(src []restUint64) []uint64 {
+	res := make([]uint64, len(src))
+	for index, value := range src {
+		res[index] = uint64(value)
+	}
+	return res
+}
+
+type restGenesis struct {
+	GenesisTime restUint64 `json:"genesis_time"`
+}
+
+type restCheckpoint struct {
+	Epoch restUint64 `json:"epoch"`
+	Root  restBytes  `json:"root"`
+}
+
+type restFinalityCheckpoints struct {
+	PreviousJustified restCheckpoint `json:"previous_justified"`
+	CurrentJustified  restCheckpoint `json:"current_justified"`
+	Finalized         restCheckpoint `json:"finalized"`
+}
+
+type restBlockHeader struct {
+	Slot          restUint64 `json:"slot"`
+	ProposerIndex restUint64 `json:"proposer_index"`
+	ParentRoot    restBytes  `json:"parent_root"`
+	StateRoot     restBytes  `json:"state_root"`
+	BodyRoot      restBytes  `json:"body_root"`
+}
+
+type restSignedBlockHeader struct {
+	Message   restBlockHeader `json:"message"`
+	Signature restBytes       `json:"signature"`
+}
+
+type restHeader struct {
+	Root      restBytes             `json:"root"`
+	Canonical bool                  `json:"canonical"`
+	Header    restSignedBlockHeader `json:"header"`
+}
+
+type restAttestationData struct {
+	Slot            restUint64     `json:"slot"`
+	Index           restUint64     `json:"index"`
+	BeaconBlockRoot restBytes      `json:"beacon_block_root"`
+	Source          restCheckpoint `json:"source"`
+	Target          restCheckpoint `json:"target"`
+}
+
+type restAttestation struct {
+	AggregationBits restBytes           `json:"aggregation_bits"`
+	Data            restAttestationData `json:"data"`
+	Signature       restBytes           `json:"signature"`
+}
+
+type restIndexedAttestation struct {
+	AttestingIndices []restUint64        `json:"attesting_indices"`
+	Data             restAttestationData `json:"data"`
+	Signature        restBytes           `json:"signature"`
+}
+
+type restProposerSlashing struct {
+	SignedHeader1 restSignedBlockHeader `json:"signed_header_1"`
+	SignedHeader2 restSignedBlockHeader `json:"signed_header_2"`
+}
+
+type restAttesterSlashing struct {
+	Attestation1 restIndexedAttestation `json:"attestation_1"`
+	Attestation2 restIndexedAttestation `json:"attestation_2"`
+}
+
+type restDeposit struct {
+	Proof []restBytes `json:"proof"`
+	Data  struct {
+		PublicKey             restBytes  `json:"pubkey"`
+		WithdrawalCredentials restBytes  `json:"withdrawal_credentials"`
+		Amount                restUint64 `json:"amount"`
+		Signature             restBytes  `json:"signature"`
+	} `json:"data"`
+}
+
+type restVoluntaryExit struct {
+	Message struct {
+		Epoch          restUint64 `json:"epoch"`
+		ValidatorIndex restUint64 `json:"validator_index"`
+	} `json:"message"`
+	Signature restBytes `json:"signature"`
+}
+
+type restBlock struct {
+	Message struct {
+		Slot          restUint64 `json:"slot"`
+		ProposerIndex restUint64 `json:"proposer_index"`
+		ParentRoot    restBytes  `json:"parent_root"`
+		StateRoot     restBytes  `json:"state_root"`
+		Body          struct {
+			RandaoReveal restBytes `json:"randao_reveal"`
+			Eth1Data     struct {
+				DepositRoot  restBytes  `json:"deposit_root"`
+				DepositCount restUint64 `json:"deposit_count"`
+				BlockHash    restBytes  `json:"block_hash"`
+			} `json:"eth1_data"`
+			Graffiti          restBytes              `json:"graffiti"`
+			ProposerSlashings []restProposerSlashing `json:"proposer_slashings"`
+			AttesterSlashings []restAttesterSlashing `json:"attester_slashings"`
+			Attestations      []restAttestation      `json:"attestations"`
+			Deposits          []restDeposit          `json:"deposits"`
+			VoluntaryExits    []restVoluntaryExit    `json:"voluntary_exits"`
+		} `json:"body"`
+	} `json:"message"`
+	Signature restBytes `json:"signature"`
+}
+
+type restValidator struct {
+	Index     restUint64 `json:"index"`
+	Balance   restUint64 `json:"balance"`
+	Status    string     `json:"status"`
+	Validator struct {
+		PublicKey                  restBytes  `json:"pubkey"`
+		WithdrawalCredentials      restBytes  `json:"withdrawal_credentials"`
+		EffectiveBalance           restUint64 `json:"effective_balance"`
+		Slashed                    bool       `json:"slashed"`
+		ActivationEligibilityEpoch restUint64 `json:"activation_eligibility_epoch"`
+		ActivationEpoch            restUint64 `json:"activation_epoch"`
+		ExitEpoch                  restUint64 `json:"exit_epoch"`
+		WithdrawableEpoch          restUint64 `json:"withdrawable_epoch"`
+	} `json:"validator"`
+}
+
+type restBalance struct {
+	Index   restUint64 `json:"index"`
+	Balance restUint64 `json:"balance"`
+}
+
+type restCommittee struct {
+	Index      restUint64   `json:"index"`
+	Slot       restUint64   `json:"slot"`
+	Validators []restUint64 `json:"validators"`
+}
+
+type restProposerDuty struct {
+	PublicKey      restBytes  `json:"pubkey"`
+	ValidatorIndex restUint64 `json:"validator_index"`
+	Slot           restUint64 `json:"slot"`
+}
+
+type restRandao struct {
+	Randao restBytes `json:"randao"`
+}
@@ -0,0 +1,198 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// restFixtures maps request URI of the standard API to the recorded response
+var restFixtures = map[string]string{
+	"/eth/v1/beacon/genesis":                                                                   "genesis.json",
+	"/eth/v1/beacon/headers/head":                                                              "headers_head.json",
+	"/eth/v1/beacon/states/head/finality_checkpoints":                                          "finality_checkpoints_head.json",
+	"/eth/v1/beacon/states/32/committees?epoch=1":                                              "committees_32_epoch_1.json",
+	"/eth/v1/validator/duties/proposer/1":                                                      "duties_proposer_1.json",
+	"/eth/v1/beacon/states/32/validators":                                                      "validators_32.json",
+	"/eth/v1/beacon/states/32/validator_balances":                                              "validator_balances_32.json",
+	"/eth/v1/beacon/states/genesis/validator_balances":                                         "validator_balances_genesis.json",
+	"/eth/v1/beacon/headers?slot=33":                                                           "headers_slot_33.json",
+	"/eth/v1/beacon/headers?slot=35":                                                           "headers_slot_35.json",
+	"/eth/v1/beacon/blocks/0x053e8fc81d875233d654bcbed41506dc8122183624ab0bd4c663c0670b059148": "block_33.json",
+	"/eth/v1/beacon/blocks/0xdf550ae0ce6676806ed701d60392300e1aec0d66940e1540dfba93c348f8f78a": "block_35.json",
+	"/eth/v1/beacon/blocks/0xa69c69d05d871167c2cc6d281d99dee2f00c76783b05761fa9f77db79cd4f48a": "block_35_orphan.json",
+}
+
+func newRestFixtureClient(t *testing.T) *RestClient {
+	SetCacheDir(t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := restFixtures[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, filepath.Join("testdata", "rest", name))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewRestClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestRestClientChainHead(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	genesis, err := client.GetGenesisTimestamp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genesis != 1606824023 {
+		t.Errorf("genesis time %v", genesis)
+	}
+
+	head, err := client.GetChainHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.HeadSlot != 63 || head.HeadEpoch != 1 {
+		t.Errorf("head slot %v epoch %v, expected 63 and 1", head.HeadSlot, head.HeadEpoch)
+	}
+	if head.FinalizedEpoch != 0 || len(head.JustifiedBlockRoot) != 32 {
+		t.Errorf("unexpected checkpoints %+v", head)
+	}
+}
+
+func TestRestClientAssignments(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	assignments, err := client.GetEpochAssignments(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assignments.FirstSlot != 32 || len(assignments.Assignments) != 32 || assignments.NumSlots != 32 {
+		t.Fatalf("unexpected assignments layout %+v", assignments)
+	}
+	if assignments.NumAssignments != 64 {
+		t.Errorf("expected 64 assignments, got %v", assignments.NumAssignments)
+	}
+	if proposer := assignments.Assignments[1].Proposer; proposer != 18 {
+		t.Errorf("proposer of slot 33 is %v, expected 18", proposer)
+	}
+	if v, _ := assignments.ValidatorAt(33, 0, 1); v != 58 {
+		t.Errorf("validator at slot 33 committee 0 position 1 is %v, expected 58", v)
+	}
+}
+
+func TestRestClientValidators(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	validators, err := client.GetEpochValidators(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validators) != 64 {
+		t.Fatalf("expected 64 validators, got %v", len(validators))
+	}
+	v := validators[5]
+	if v.Index != 5 || v.Balance != 32000005000 || v.Balance1d != 32000000000 || v.EffectiveBalance != 32000000000 {
+		t.Errorf("unexpected validator %+v", v)
+	}
+	if len(v.PublicKey) != 48 || len(v.WithdrawalCredentials) != 32 {
+		t.Errorf("keys are not decoded: %x %x", v.PublicKey, v.WithdrawalCredentials)
+	}
+}
+
+func TestRestClientBlocksBySlot(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	blocks, err := client.GetBlocksBySlot(34)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 0 {
+		t.Errorf("expected no blocks at missed slot, got %v", len(blocks))
+	}
+
+	blocks, err = client.GetBlocksBySlot(33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %v", len(blocks))
+	}
+	b := blocks[0]
+	if b.Slot != 33 || b.Proposer != 18 || !b.Canonical || b.Status != 1 {
+		t.Errorf("unexpected block header %+v", b)
+	}
+	if !bytes.HasPrefix(b.Graffiti, []byte("fixture")) {
+		t.Errorf("graffiti %q", b.Graffiti)
+	}
+	if len(b.Attestations) != 1 || !reflect.DeepEqual(b.Attestations[0].Attesters, []uint64{11, 48}) {
+		t.Errorf("unexpected attestations %+v", b.Attestations)
+	}
+	if len(b.Deposits) != 1 || len(b.Deposits[0].Proof) != 33 || b.Deposits[0].Amount != 32000000000 {
+		t.Errorf("unexpected deposits %+v", b.Deposits)
+	}
+	if len(b.VoluntaryExits) != 1 || b.VoluntaryExits[0].ValidatorIndex != 3 {
+		t.Errorf("unexpected exits %+v", b.VoluntaryExits)
+	}
+
+	blocks, err = client.GetBlocksBySlot(35)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || !blocks[0].Canonical || blocks[1].Canonical {
+		t.Fatalf("expected canonical and orphaned blocks, got %+v", blocks)
+	}
+	if !reflect.DeepEqual(blocks[0].Attestations[0].Attesters, []uint64{58}) {
+		t.Errorf("unexpected attesters %v", blocks[0].Attestations[0].Attesters)
+	}
+	if len(blocks[1].ProposerSlashings) != 1 || blocks[1].ProposerSlashings[0].ProposerIndex != 44 {
+		t.Errorf("unexpected proposer slashings %+v", blocks[1].ProposerSlashings)
+	}
+}
+
+func TestRestClientParticipationNotSupported(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	_, err := client.GetValidatorParticipation(1)
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestRestClientEpochData(t *testing.T) {
+	client := newRestFixtureClient(t)
+
+	data, err := client.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Epoch != 1 || data.EpochParticipationStats != nil {
+		t.Errorf("unexpected epoch %v participation %+v", data.Epoch, data.EpochParticipationStats)
+	}
+	if len(data.Validators) != 64 || data.ValidatorAssignments.NumAssignments != 64 {
+		t.Errorf("%d validators, %d assignments", len(data.Validators), data.ValidatorAssignments.NumAssignments)
+	}
+	if len(data.Blocks[33]) != 1 || len(data.Blocks[35]) != 2 {
+		t.Errorf("unexpected blocks of slots 33 and 35")
+	}
+	for _, b := range data.Blocks[33] {
+		if b.Proposer != 18 || !b.Canonical {
+			t.Errorf("unexpected block of slot 33 %+v", b)
+		}
+	}
+	if p, ok := data.Proposals.Get(34); !ok || p.Status != types.ProposalMissed {
+		t.Errorf("slot 34 is not missed %+v", p)
+	}
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"fmt"
+)
+
+// FnRewards is the balance changes of the validators since the epoch till the next one
+func FnRewards(epoch uint64) string {
+	return CachePath(fmt.Sprintf("%d.rewards.gz", epoch))
+}
+
+func HasRewards(epoch uint64) bool {
+	return hasFile(FnRewards(epoch))
+}
+
+func LoadRewards(epoch uint64) (*types.EpochRewards, error) {
+	var out types.EpochRewards
+	if err := loadGob(FnRewards(epoch), &out); err != nil {
+		return nil, cacheError(err, "rewards", epoch)
+	}
+	return &out, nil
+}
+
+func SaveRewards(epoch uint64, src *types.EpochRewards) error {
+	if src == nil {
+		return nil
+	}
+	return saveGob(FnRewards(epoch), src)
+}
+
+// FnDayRewards is the rollup of the rewards of the epochs of the day since genesis,
+// it is not prefixed with the epoch, so it is not sealed with the epochs
+func FnDayRewards(day uint64) string {
+	return CachePath(fmt.Sprintf("day%d.rewards.gz", day))
+}
+
+func HasDayRewards(day uint64) bool {
+	return hasFile(FnDayRewards(day))
+}
+
+func LoadDayRewards(day uint64) (*types.DayRewards, error) {
+	var out types.DayRewards
+	if err := loadGob(FnDayRewards(day), &out); err != nil {
+		epoch := day * types.EpochsPerDay
+		return nil, cacheError(err, fmt.Sprintf("rewards of day %d", day), epoch)
+	}
+	return &out, nil
+}
+
+func SaveDayRewards(day uint64, src *types.DayRewards) error {
+	if src == nil {
+		return nil
+	}
+	return saveGob(FnDayRewards(day), src)
+}
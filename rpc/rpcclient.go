@@ -3,7 +3,9 @@ package rpc
 import (
 	"beaconchain/types"
 	"context"
+	"errors"
 	"fmt"
+	"path"
 	"sync"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
 	"github.com/prysmaticlabs/go-bitfield"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
 
 	empty "github.com/golang/protobuf/ptypes/empty"
@@ -21,22 +24,37 @@ var logger = logrus.New().WithField("module", "rpc")
 
 // PrysmClient holds information about the Prysm Client
 type PrysmClient struct {
-	client              ethpb.BeaconChainClient
-	nodeClient          ethpb.NodeClient
-	conn                *grpc.ClientConn
-	assignmentsCache    *lru.Cache
-	assignmentsCacheMux *sync.Mutex
-	newBlockChan        chan *types.Block
+	client           ethpb.BeaconChainClient
+	nodeClient       ethpb.NodeClient
+	conn             *grpc.ClientConn
+	assignmentsCache *lru.Cache
+	flights          singleflight.Group
+	parallelism      int
+	proposerDuties   ProposerDutiesSource
+	randaoMixes      RandaoMixSource
+	newBlockChan     chan *types.Block
+	chainHeadChan    chan *types.ChainHead
+	publishedRoots   *lru.Cache
+	lastSlot         uint64
+	ctx              context.Context
+	cancel           context.CancelFunc
+	streams          sync.WaitGroup
+	subscribeOnce    sync.Once
+	closeOnce        sync.Once
+	finality
+	lookbacks
 }
 
-// NewPrysmClient is used for a new Prysm client connection
-func NewPrysmClient(endpoint string) (*PrysmClient, error) {
+// NewPrysmClient is used for a new Prysm client connection,
+// extra dial options are appended to the defaults
+func NewPrysmClient(endpoint string, opts ...grpc.DialOption) (*PrysmClient, error) {
 	dialOpts := []grpc.DialOption{
 		grpc.WithInsecure(),
 		// Maximum receive value 128 MB
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(128 * 1024 * 1024)),
+		grpc.WithChainUnaryInterceptor(nodeErrors),
 	}
-	conn, err := grpc.Dial(endpoint, dialOpts...)
+	conn, err := grpc.Dial(endpoint, append(dialOpts, opts...)...)
 
 	if err != nil {
 		return nil, err
@@ -47,21 +65,46 @@ func NewPrysmClient(endpoint string) (*PrysmClient, error) {
 
 	// logger.Printf("gRPC connection to backend node established")
 	client := &PrysmClient{
-		client:              chainClient,
-		nodeClient:          nodeClient,
-		conn:                conn,
-		assignmentsCacheMux: &sync.Mutex{},
-		newBlockChan:        make(chan *types.Block, 1000),
-	}
+		client:        chainClient,
+		nodeClient:    nodeClient,
+		conn:          conn,
+		parallelism:   cfgParallelism,
+		newBlockChan:  make(chan *types.Block, 1000),
+		chainHeadChan: make(chan *types.ChainHead, 100),
+	}
+	client.chainHead = client.GetChainHead
+	client.ctx, client.cancel = context.WithCancel(context.Background())
 	client.assignmentsCache, _ = lru.New(10)
+	client.publishedRoots, _ = lru.New(1024)
 	return client, nil
 }
 
-// Close will close a Prysm client connection
+// nodeErrors wraps the failed requests into NodeError
+func nodeErrors(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+		return &NodeError{Method: path.Base(method), Err: err}
+	}
+	return nil
+}
+
+// Close will stop the streams and close a Prysm client connection,
+// the block and chain head channels are closed as well
 func (pc *PrysmClient) Close() {
-	pc.conn.Close()
+	pc.closeOnce.Do(func() {
+		pc.cancel()
+		pc.streams.Wait()
+		close(pc.newBlockChan)
+		close(pc.chainHeadChan)
+		pc.conn.Close()
+	})
+}
+
+// SetParallelism limits the number of concurrent requests to the node while assembling an epoch
+func (pc *PrysmClient) SetParallelism(limit int) {
+	pc.parallelism = limit
 }
 
+// GetNewBlockChan returns the channel of the new blocks, filled after Subscribe
 func (pc *PrysmClient) GetNewBlockChan() chan *types.Block {
 	return pc.newBlockChan
 }
@@ -84,7 +127,12 @@ func (pc *PrysmClient) GetChainHead() (*types.ChainHead, error) {
 	if err != nil {
 		return nil, err
 	}
+	head := newChainHead(headResponse)
+	pc.seen(head)
+	return head, nil
+}
 
+func newChainHead(headResponse *ethpb.ChainHead) *types.ChainHead {
 	return &types.ChainHead{
 		HeadSlot:                   uint64(headResponse.HeadSlot),
 		HeadEpoch:                  uint64(headResponse.HeadEpoch),
@@ -98,7 +146,7 @@ func (pc *PrysmClient) GetChainHead() (*types.ChainHead, error) {
 		PreviousJustifiedSlot:      uint64(headResponse.PreviousJustifiedSlot),
 		PreviousJustifiedEpoch:     uint64(headResponse.PreviousJustifiedEpoch),
 		PreviousJustifiedBlockRoot: headResponse.PreviousJustifiedBlockRoot,
-	}, nil
+	}
 }
 
 func validatorIndexes(src []eth2types.ValidatorIndex) []uint64 {
@@ -116,7 +164,7 @@ func (pc *PrysmClient) GetValidatorQueue() (*types.ValidatorQueue, error) {
 	validators, err := pc.client.GetValidatorQueue(context.Background(), &empty.Empty{})
 
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validator queue data: %v", err)
+		return nil, fmt.Errorf("error retrieving validator queue data: %w", err)
 	}
 	return &types.ValidatorQueue{
 		ChurnLimit:                 validators.ChurnLimit,
@@ -135,36 +183,20 @@ func (pc *PrysmClient) GetAttestationPool() ([]*types.Attestation, error) {
 
 	attestations := []*types.Attestation{}
 
-	for {
+	for pages := 0; ; pages++ {
 		attestationPoolResponse, err = pc.client.AttestationPool(
 			context.Background(), &ethpb.AttestationPoolRequest{
 				PageSize:  cfgPageSize,
 				PageToken: attestationPoolResponse.NextPageToken,
 			})
 		if err != nil {
-			return nil, err
+			return nil, pageError("attestation pool", 0, pages, err)
 		}
 		if attestationPoolResponse.TotalSize == 0 {
 			break
 		}
 		for _, attestation := range attestationPoolResponse.Attestations {
-			attestations = append(attestations, &types.Attestation{
-				AggregationBits: attestation.AggregationBits,
-				Data: &types.AttestationData{
-					Slot:            uint64(attestation.Data.Slot),
-					CommitteeIndex:  uint64(attestation.Data.CommitteeIndex),
-					BeaconBlockRoot: attestation.Data.BeaconBlockRoot,
-					Source: &types.Checkpoint{
-						Epoch: uint64(attestation.Data.Source.Epoch),
-						Root:  attestation.Data.Source.Root,
-					},
-					Target: &types.Checkpoint{
-						Epoch: uint64(attestation.Data.Target.Epoch),
-						Root:  attestation.Data.Target.Root,
-					},
-				},
-				Signature: attestation.Signature,
-			})
+			attestations = append(attestations, types.NewAttestationFromPB(attestation))
 		}
 		if attestationPoolResponse.NextPageToken == "" {
 			break
@@ -174,19 +206,33 @@ func (pc *PrysmClient) GetAttestationPool() ([]*types.Attestation, error) {
 	return attestations, nil
 }
 
-// GetEpochAssignments will get the epoch assignments from a Prysm client
+// GetEpochAssignments will get the epoch assignments from a Prysm client,
+// concurrent calls for the same epoch share a single fetch
 func (pc *PrysmClient) GetEpochAssignments(epoch uint64) (*types.Assignments, error) {
-	pc.assignmentsCacheMux.Lock()
-	defer pc.assignmentsCacheMux.Unlock()
+	// LRU is synchronized on its own, the lock is held only during the lookup
+	cachedValue, found := pc.assignmentsCache.Get(epoch)
+	if found && pc.usable(FnAssignments(epoch)) {
+		return cachedValue.(*types.Assignments), nil
+	}
+	v, err, _ := pc.flights.Do(fmt.Sprintf("assignments-%d", epoch), func() (interface{}, error) {
+		return pc.fetchEpochAssignments(epoch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.Assignments), nil
+}
 
+func (pc *PrysmClient) fetchEpochAssignments(epoch uint64) (*types.Assignments, error) {
+	// the previous flight could have finished between the lookup and the start of this one
 	cachedValue, found := pc.assignmentsCache.Get(epoch)
-	if found {
+	if found && pc.usable(FnAssignments(epoch)) {
 		return cachedValue.(*types.Assignments), nil
 	}
 
 	var err error
 
-	if HasAssignments(epoch) {
+	if HasAssignments(epoch) && pc.usable(FnAssignments(epoch)) {
 		out, err := LoadAssignments(epoch)
 		if err == nil {
 			logger.Debugf("loaded epoch %d cached assignments, %v slots %v assignments",
@@ -194,7 +240,7 @@ func (pc *PrysmClient) GetEpochAssignments(epoch uint64) (*types.Assignments, er
 			pc.assignmentsCache.Add(epoch, out)
 			return out, nil
 		} else {
-			logger.Errorf("LoadAssignments failure", err)
+			logger.Errorf("LoadAssignments failure: %v", err)
 		}
 		// } else if HasAssignmentsPB(epoch) {
 		// pb, err := LoadAssignmentsPB(epoch, "")
@@ -211,6 +257,29 @@ func (pc *PrysmClient) GetEpochAssignments(epoch uint64) (*types.Assignments, er
 
 	logger.Infof("caching assignments for epoch %v started", epoch)
 	start := time.Now()
+	var out *types.Assignments
+	switch {
+	case pc.randaoMixes != nil:
+		out, err = pc.fetchComputedAssignments(epoch)
+	case pc.proposerDuties != nil:
+		out, err = pc.fetchCommitteeAssignments(epoch)
+	default:
+		out, err = pc.fetchValidatorAssignments(epoch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Assignments) > 0 {
+		SaveAssignments(epoch, out)
+		pc.saved(FnAssignments(epoch), epoch)
+		pc.assignmentsCache.Add(epoch, out)
+	}
+	logger.Infof("assignments for epoch %v took %v", epoch, time.Since(start))
+	return out, nil
+}
+
+// fetchValidatorAssignments pages through the assignments of every validator of the epoch
+func (pc *PrysmClient) fetchValidatorAssignments(epoch uint64) (*types.Assignments, error) {
 	pbRequest := &ethpb.ListValidatorAssignmentsRequest{
 		PageSize:    cfgPageSize,
 		QueryFilter: &ethpb.ListValidatorAssignmentsRequest_Epoch{Epoch: eth2types.Epoch(epoch)}}
@@ -224,7 +293,7 @@ func (pc *PrysmClient) GetEpochAssignments(epoch uint64) (*types.Assignments, er
 		// Retrieve the validator assignments for the epoch
 		pbResponse, err := pc.client.ListValidatorAssignments(context.Background(), pbRequest)
 		if err != nil {
-			return nil, err
+			return nil, pageError("assignments", epoch, len(chunks), err)
 		}
 		chunks = append(chunks, pbResponse)
 		if pbResponse.NextPageToken == "" {
@@ -233,66 +302,42 @@ func (pc *PrysmClient) GetEpochAssignments(epoch uint64) (*types.Assignments, er
 		pbRequest.PageToken = pbResponse.NextPageToken
 		numRequests++
 	}
-
-	out := NewAssignmentsFromPB(epoch, chunks)
-	// SaveAssignmentsPB(epoch, pbResponse) // temp
-	if len(out.Assignments) > 0 {
-		SaveAssignments(epoch, out)
-		pc.assignmentsCache.Add(epoch, out)
-	}
-	logger.Infof("== %d REQUESTS for assignments for epoch %v took %v ", numRequests, epoch, time.Since(start))
-	return out, err
+	logger.Debugf("%d requests for assignments of epoch %v", numRequests, epoch)
+	return NewAssignmentsFromPB(epoch, chunks), nil
 }
 
+// GetEpochValidators returns validator set of the epoch, with the balances of the lookback windows,
+// concurrent calls for the same epoch share a single fetch
 func (pc *PrysmClient) GetEpochValidators(epoch uint64) ([]*types.Validator, error) {
-	out := make([]*types.Validator, 0)
+	v, err, _ := pc.flights.Do(fmt.Sprintf("validators-%d", epoch), func() (interface{}, error) {
+		return pc.fetchEpochValidators(epoch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*types.Validator), nil
+}
 
-	if HasValidators(epoch) {
+func (pc *PrysmClient) fetchEpochValidators(epoch uint64) ([]*types.Validator, error) {
+	if HasValidators(epoch) && pc.usable(FnValidators(epoch)) {
 		res, err := LoadValidators(epoch)
 		if err == nil {
-			for _, v := range res {
-				out = append(out, v.ToValidator())
+			var out []*types.Validator
+			if out, err = withCachedLookback(epoch, res); err == nil {
+				return out, nil
 			}
-			return out, nil
 		}
+		logger.Errorf("cached validators of epoch %d failure: %v", epoch, err)
 	}
 
 	cached := make([]types.ValidatorF, 0)
 
 	since := time.Now()
-	start := time.Now()
 	validatorBalances, err := pc.GetBalancesForEpoch(int64(epoch))
 	if err != nil {
 		return nil, err
 	}
-	logger.Printf("retrieved data for %v validator balances for epoch %v took %v", len(validatorBalances), epoch, time.Since(start))
-
-	// Retrieve the validator balances for the n-1d epoch
-	start = time.Now()
-	epoch1d := int64(epoch) - 225
-	validatorBalances1d, err := pc.GetBalancesForEpoch(epoch1d)
-	if err != nil {
-		return nil, err
-	}
-	logger.Printf("retrieved data for %v validator balances for 1d epoch %v took %v", len(validatorBalances), epoch1d, time.Since(start))
-
-	// Retrieve the validator balances for the n-7d epoch
-	start = time.Now()
-	epoch7d := int64(epoch) - 225*7
-	validatorBalances7d, err := pc.GetBalancesForEpoch(epoch7d)
-	if err != nil {
-		return nil, err
-	}
-	logger.Printf("retrieved data for %v validator balances for 7d epoch %v took %v", len(validatorBalances), epoch7d, time.Since(start))
-
-	// Retrieve the validator balances for the n-7d epoch
-	start = time.Now()
-	epoch31d := int64(epoch) - 225*31
-	validatorBalances31d, err := pc.GetBalancesForEpoch(epoch31d)
-	if err != nil {
-		return nil, err
-	}
-	logger.Printf("retrieved data for %v validator balances for 31d epoch %v took %v", len(validatorBalances), epoch31d, time.Since(start))
+	logger.Printf("retrieved data for %v validator balances for epoch %v took %v", len(validatorBalances), epoch, time.Since(since))
 
 	validatorResponse := &ethpb.Validators{}
 	validatorRequest := &ethpb.ListValidatorsRequest{
@@ -302,12 +347,12 @@ func (pc *PrysmClient) GetEpochValidators(epoch uint64) ([]*types.Validator, err
 	if epoch == 0 {
 		validatorRequest.QueryFilter = &ethpb.ListValidatorsRequest_Genesis{Genesis: true}
 	}
-	for {
+	for pages := 0; ; pages++ {
 		validatorRequest.PageToken = validatorResponse.NextPageToken
 		validatorResponse, err = pc.client.ListValidators(context.Background(), validatorRequest)
 		if err != nil {
-			logger.Errorf("error retrieving validator response: %v", err)
-			break
+			// the incomplete validator set is neither returned nor cached
+			return nil, pageError("validators", epoch, pages, err)
 		}
 		if validatorResponse.TotalSize == 0 {
 			break
@@ -323,24 +368,8 @@ func (pc *PrysmClient) GetEpochValidators(epoch uint64) ([]*types.Validator, err
 					Errorf("error retrieving validator balance")
 				continue
 			}
-			val := types.ValidatorF{
-				Index:                      uint64(validator.Index),
-				Balance:                    balance,
-				EffectiveBalance:           validator.Validator.EffectiveBalance,
-				Slashed:                    validator.Validator.Slashed,
-				ActivationEligibilityEpoch: uint64(validator.Validator.ActivationEligibilityEpoch),
-				ActivationEpoch:            uint64(validator.Validator.ActivationEpoch),
-				ExitEpoch:                  uint64(validator.Validator.ExitEpoch),
-				WithdrawableEpoch:          uint64(validator.Validator.WithdrawableEpoch),
-			}
-			// copy(val.Status[:], "")
-			copy(val.PublicKey[:], validator.Validator.PublicKey)
-			copy(val.WithdrawalCredentials[:], validator.Validator.WithdrawalCredentials)
-			val.Balance1d = validatorBalances1d[uint64(validator.Index)]
-			val.Balance7d = validatorBalances7d[uint64(validator.Index)]
-			val.Balance31d = validatorBalances31d[uint64(validator.Index)]
-
-			out = append(out, val.ToValidator())
+			val := types.NewValidatorFFromPB(uint64(validator.Index), balance, validator.Validator)
+			val.Status = val.StatusAt(epoch)
 			cached = append(cached, val)
 		}
 
@@ -349,13 +378,25 @@ func (pc *PrysmClient) GetEpochValidators(epoch uint64) ([]*types.Validator, err
 		}
 	}
 
+	// balances of the lookback windows are fetched once the activation epochs are known
+	lookback, err := pc.balanceLookback(&pc.finality, pc.parallelism, epoch, cached, pc.GetBalancesForEpoch)
+	if err != nil {
+		return nil, err
+	}
+	out := withLookback(cached, lookback)
 	logger.Printf("list of %v validators for epoch %v took %v", len(out), epoch, time.Since(since))
 	SaveValidators(epoch, cached)
+	pc.saved(FnValidators(epoch), epoch)
 	return out, nil
 }
 
 // GetEpochData will get the epoch data from a Prysm client
 func (pc *PrysmClient) GetEpochData(epoch uint64) (*types.EpochData, error) {
+	return assembleEpochData(pc, epoch)
+}
+
+// assembleEpochData gets the epoch data from the source, filling up missed and scheduled blocks
+func assembleEpochData(source BeaconSource, epoch uint64) (*types.EpochData, error) {
 	var err error
 
 	data := &types.EpochData{}
@@ -363,91 +404,53 @@ func (pc *PrysmClient) GetEpochData(epoch uint64) (*types.EpochData, error) {
 	// Retrieve the validator balances for the requested epoch
 
 	start := time.Now()
-	data.ValidatorAssignments, err = pc.GetEpochAssignments(epoch)
+	data.ValidatorAssignments, err = source.GetEpochAssignments(epoch)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving assignments for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error retrieving assignments for epoch %v: %w", epoch, err)
 	}
 	logger.Printf("retrieved validator assignment data for epoch %v took %v", epoch, time.Since(start))
 
 	// Retrieve all blocks for the epoch
-	data.Blocks = make(map[uint64]map[string]*types.Block)
-
-	for slot := epoch * cfgSlotsPerEpoch; slot <= (epoch+1)*cfgSlotsPerEpoch-1; slot++ {
-		blocks, err := pc.GetBlocksBySlot(slot)
-
-		if err != nil {
-			return nil, err
-		}
-
-		for _, block := range blocks {
-			if data.Blocks[block.Slot] == nil {
-				data.Blocks[block.Slot] = make(map[string]*types.Block)
-			}
-			data.Blocks[block.Slot][fmt.Sprintf("%x", block.BlockRoot)] = block
-		}
-	}
-	logger.Printf("retrieved %v blocks for epoch %v", len(data.Blocks), epoch)
-
-	// Fill up missed and scheduled blocks
-	for slotIndex, a := range data.ValidatorAssignments.Assignments {
-		slot := data.ValidatorAssignments.FirstSlot + uint64(slotIndex)
-		_, found := data.Blocks[slot]
-		if !found {
-			// Proposer was assigned but did not yet propose a block
-			data.Blocks[slot] = make(map[string]*types.Block)
-			data.Blocks[slot]["0x0"] = &types.Block{
-				Status:            0,
-				Proposer:          a.Proposer,
-				BlockRoot:         []byte{0x0},
-				Slot:              slot,
-				ParentRoot:        []byte{},
-				StateRoot:         []byte{},
-				Signature:         []byte{},
-				RandaoReveal:      []byte{},
-				Graffiti:          []byte{},
-				BodyRoot:          []byte{},
-				Eth1Data:          &types.Eth1Data{},
-				ProposerSlashings: make([]*types.ProposerSlashing, 0),
-				AttesterSlashings: make([]*types.AttesterSlashing, 0),
-				Attestations:      make([]*types.Attestation, 0),
-				Deposits:          make([]*types.Deposit, 0),
-				VoluntaryExits:    make([]*types.VoluntaryExit, 0),
-			}
-
-			if SlotToTime(slot).After(time.Now().Add(time.Second * -60)) {
-				// Block is in the future, set status to scheduled
-				data.Blocks[slot]["0x0"].Status = 0
-				data.Blocks[slot]["0x0"].BlockRoot = []byte{0x0}
-			} else {
-				// Block is in the past, set status to missed
-				data.Blocks[slot]["0x0"].Status = 2
-				data.Blocks[slot]["0x0"].BlockRoot = []byte{0x1}
-			}
-		}
+	start = time.Now()
+	data.Blocks, err = slotBlocks(source, data.ValidatorAssignments)
+	if err != nil {
+		return nil, err
 	}
+	logger.Printf("retrieved %v blocks for epoch %v took %v", len(data.Blocks), epoch, time.Since(start))
+	data.Proposals = types.NewEpochProposals(epoch, data.ValidatorAssignments, data.Blocks)
 
 	// Retrieve the validator set for the epoch
-	data.Validators, err = pc.GetEpochValidators(epoch)
+	data.Validators, err = source.GetEpochValidators(epoch)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validators list for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error retrieving validators list for epoch %v: %w", epoch, err)
 	}
 	logger.Printf("retrieved data for %v validators for epoch %v", len(data.Validators), epoch)
 
-	data.EpochParticipationStats, err = pc.GetValidatorParticipation(epoch)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving epoch participation statistics for epoch %v: %v", epoch, err)
+	// participation is left out, when the node API does not provide it
+	data.EpochParticipationStats, err = source.GetValidatorParticipation(epoch)
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		return nil, fmt.Errorf("error retrieving epoch participation statistics for epoch %v: %w", epoch, err)
 	}
 
 	return data, nil
 }
 
+// GetBalancesForEpoch returns map of validator index to its balance at the epoch,
+// concurrent calls for the same epoch share a single fetch and the same map, which must not be modified
 func (pc *PrysmClient) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
-
 	if epoch < 0 {
 		epoch = 0
 	}
+	v, err, _ := pc.flights.Do(fmt.Sprintf("balances-%d", epoch), func() (interface{}, error) {
+		return pc.fetchBalancesForEpoch(epoch)
+	})
+	balances, _ := v.(map[uint64]uint64)
+	return balances, err
+}
 
-	if HasBalances(epoch) {
+func (pc *PrysmClient) fetchBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
+
+	if HasBalances(epoch) && pc.usable(FnBalances(epoch)) {
 		r, err := LoadBalances(epoch)
 		if err == nil {
 			sum := uint64(0)
@@ -455,8 +458,9 @@ func (pc *PrysmClient) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, erro
 				sum = sum + v
 			}
 			logger.Debugf("loaded epoch %d total balances %v", epoch, sum)
+			return r, nil
 		}
-		return r, err
+		logger.Errorf("LoadBalances failure: %v", err)
 	}
 
 	// if there is a local file with array of uint64, load it
@@ -472,12 +476,12 @@ func (pc *PrysmClient) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, erro
 	if epoch == 0 {
 		validatorBalancesRequest.QueryFilter = &ethpb.ListValidatorBalancesRequest_Genesis{Genesis: true}
 	}
-	for {
+	for pages := 0; ; pages++ {
 		validatorBalancesRequest.PageToken = validatorBalancesResponse.NextPageToken
 		validatorBalancesResponse, err = pc.client.ListValidatorBalances(context.Background(), validatorBalancesRequest)
 		if err != nil {
-			logger.Printf("error retrieving validator balances for epoch %v: %v", epoch, err)
-			break
+			// the incomplete balances are neither returned nor cached
+			return nil, pageError("balances", uint64(epoch), pages, err)
 		}
 		if validatorBalancesResponse.TotalSize == 0 {
 			break
@@ -498,8 +502,42 @@ func (pc *PrysmClient) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, erro
 		}
 		logger.Debugf("saved epoch %d totals: %v for %d validators\n", epoch, sum, len(validatorBalances))
 		SaveBalances(epoch, validatorBalances)
+		pc.saved(FnBalances(epoch), uint64(epoch))
+	}
+	return validatorBalances, nil
+}
+
+// GetEpochBlocks returns all blocks of the epoch, canonical or not, from the cache or from the node,
+// the epoch is cached once all its slots are behind the head
+func (pc *PrysmClient) GetEpochBlocks(epoch uint64) ([]*types.Block, error) {
+	v, err, _ := pc.flights.Do(fmt.Sprintf("blocks-%d", epoch), func() (interface{}, error) {
+		if HasBlocks(epoch) && pc.usable(FnBlocks(epoch)) {
+			blocks, err := LoadBlocks(epoch)
+			if err == nil {
+				return blocks, nil
+			}
+			logger.Errorf("LoadBlocks failure: %v", err)
+		}
+		// slots are requested concurrently
+		blocks, err := fetchBlocks(pc.parallelism, epoch, pc.GetBlocksBySlot)
+		if err != nil {
+			return nil, err
+		}
+		blocks = retainBlocks(epoch, blocks, FnBlocks(epoch), FnReplacedBlocks(epoch))
+		if pc.finished(epoch) {
+			if err := SaveBlocks(epoch, blocks); err != nil {
+				logger.Errorf("SaveBlocks failure: %v", err)
+			} else {
+				savedBlocks(epoch)
+			}
+			pc.saved(FnBlocks(epoch), epoch)
+		}
+		return blocks, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return validatorBalances, err
+	return v.([]*types.Block), nil
 }
 
 // GetBlocksBySlot will get blocks by slot from a Prysm client
@@ -523,16 +561,6 @@ func (pc *PrysmClient) GetBlocksBySlot(slot uint64) ([]*types.Block, error) {
 	}
 
 	for _, block := range blocksResponse.BlockContainers {
-
-		// Make sure that blocks from the genesis epoch have their Eth1Data field set
-		if block.Block.Block.Body.Eth1Data == nil {
-			block.Block.Block.Body.Eth1Data = &ethpb.Eth1Data{
-				DepositRoot:  []byte{},
-				DepositCount: 0,
-				BlockHash:    []byte{},
-			}
-		}
-
 		b, err := pc.parseRpcBlock(block)
 		if err != nil {
 			return nil, err
@@ -574,171 +602,101 @@ func (pc *PrysmClient) GetBlockStatusByEpoch(epoch uint64) ([]*types.CanonBlock,
 	return blocks, nil
 }
 
-func (pc *PrysmClient) parseRpcBlock(block *ethpb.BeaconBlockContainer) (*types.Block, error) {
-	b := &types.Block{
-		Status:       1,
-		Canonical:    block.Canonical,
-		BlockRoot:    block.BlockRoot,
-		Slot:         uint64(block.Block.Block.Slot),
-		ParentRoot:   block.Block.Block.ParentRoot,
-		StateRoot:    block.Block.Block.StateRoot,
-		Signature:    block.Block.Signature,
-		RandaoReveal: block.Block.Block.Body.RandaoReveal,
-		Graffiti:     block.Block.Block.Body.Graffiti,
-		Eth1Data: &types.Eth1Data{
-			DepositRoot:  block.Block.Block.Body.Eth1Data.DepositRoot,
-			DepositCount: block.Block.Block.Body.Eth1Data.DepositCount,
-			BlockHash:    block.Block.Block.Body.Eth1Data.BlockHash,
-		},
-		ProposerSlashings: make([]*types.ProposerSlashing, len(block.Block.Block.Body.ProposerSlashings)),
-		AttesterSlashings: make([]*types.AttesterSlashing, len(block.Block.Block.Body.AttesterSlashings)),
-		Attestations:      make([]*types.Attestation, len(block.Block.Block.Body.Attestations)),
-		Deposits:          make([]*types.Deposit, len(block.Block.Block.Body.Deposits)),
-		VoluntaryExits:    make([]*types.VoluntaryExit, len(block.Block.Block.Body.VoluntaryExits)),
-		Proposer:          uint64(block.Block.Block.ProposerIndex),
-	}
-
-	for i, proposerSlashing := range block.Block.Block.Body.ProposerSlashings {
-		b.ProposerSlashings[i] = &types.ProposerSlashing{
-			ProposerIndex: uint64(proposerSlashing.Header_1.Header.ProposerIndex),
-			Header1: &types.Block{
-				Slot:       uint64(proposerSlashing.Header_1.Header.Slot),
-				ParentRoot: proposerSlashing.Header_1.Header.ParentRoot,
-				StateRoot:  proposerSlashing.Header_1.Header.StateRoot,
-				Signature:  proposerSlashing.Header_1.Signature,
-				BodyRoot:   proposerSlashing.Header_1.Header.BodyRoot,
-			},
-			Header2: &types.Block{
-				Slot:       uint64(proposerSlashing.Header_2.Header.Slot),
-				ParentRoot: proposerSlashing.Header_2.Header.ParentRoot,
-				StateRoot:  proposerSlashing.Header_2.Header.StateRoot,
-				Signature:  proposerSlashing.Header_2.Signature,
-				BodyRoot:   proposerSlashing.Header_2.Header.BodyRoot,
-			},
-		}
-	}
-
-	for i, attesterSlashing := range block.Block.Block.Body.AttesterSlashings {
-		b.AttesterSlashings[i] = &types.AttesterSlashing{
-			Attestation1: &types.IndexedAttestation{
-				Data: &types.AttestationData{
-					Slot:            uint64(attesterSlashing.Attestation_1.Data.Slot),
-					CommitteeIndex:  uint64(attesterSlashing.Attestation_1.Data.CommitteeIndex),
-					BeaconBlockRoot: attesterSlashing.Attestation_1.Data.BeaconBlockRoot,
-					Source: &types.Checkpoint{
-						Epoch: uint64(attesterSlashing.Attestation_1.Data.Source.Epoch),
-						Root:  attesterSlashing.Attestation_1.Data.Source.Root,
-					},
-					Target: &types.Checkpoint{
-						Epoch: uint64(attesterSlashing.Attestation_1.Data.Target.Epoch),
-						Root:  attesterSlashing.Attestation_1.Data.Target.Root,
-					},
-				},
-				Signature:        attesterSlashing.Attestation_1.Signature,
-				AttestingIndices: attesterSlashing.Attestation_1.AttestingIndices,
-			},
-			Attestation2: &types.IndexedAttestation{
-				Data: &types.AttestationData{
-					Slot:            uint64(attesterSlashing.Attestation_2.Data.Slot),
-					CommitteeIndex:  uint64(attesterSlashing.Attestation_2.Data.CommitteeIndex),
-					BeaconBlockRoot: attesterSlashing.Attestation_2.Data.BeaconBlockRoot,
-					Source: &types.Checkpoint{
-						Epoch: uint64(attesterSlashing.Attestation_2.Data.Source.Epoch),
-						Root:  attesterSlashing.Attestation_2.Data.Source.Root,
-					},
-					Target: &types.Checkpoint{
-						Epoch: uint64(attesterSlashing.Attestation_2.Data.Target.Epoch),
-						Root:  attesterSlashing.Attestation_2.Data.Target.Root,
-					},
-				},
-				Signature:        attesterSlashing.Attestation_2.Signature,
-				AttestingIndices: attesterSlashing.Attestation_2.AttestingIndices,
-			},
-		}
-	}
-
-	for i, attestation := range block.Block.Block.Body.Attestations {
-		a := &types.Attestation{
-			AggregationBits: attestation.AggregationBits,
-			Data: &types.AttestationData{
-				Slot:            uint64(attestation.Data.Slot),
-				CommitteeIndex:  uint64(attestation.Data.CommitteeIndex),
-				BeaconBlockRoot: attestation.Data.BeaconBlockRoot,
-				Source: &types.Checkpoint{
-					Epoch: uint64(attestation.Data.Source.Epoch),
-					Root:  attestation.Data.Source.Root,
-				},
-				Target: &types.Checkpoint{
-					Epoch: uint64(attestation.Data.Target.Epoch),
-					Root:  attestation.Data.Target.Root,
-				},
-			},
-			Signature: attestation.Signature,
-		}
+// GetMinimalBlocksByEpoch returns all blocks of the epoch known to the node, canonical or not
+func (pc *PrysmClient) GetMinimalBlocksByEpoch(epoch uint64) ([]*types.MinimalBlock, error) {
+	blocks := make([]*types.MinimalBlock, 0)
 
-		aggregationBits := bitfield.Bitlist(a.AggregationBits)
-		assignments, err := pc.GetEpochAssignments(a.Data.Slot / cfgSlotsPerEpoch)
+	blocksResponse := &ethpb.ListBlocksResponse{}
+	blocksRequest := &ethpb.ListBlocksRequest{
+		PageSize:    cfgPageSize,
+		QueryFilter: &ethpb.ListBlocksRequest_Epoch{Epoch: eth2types.Epoch(epoch)}}
+	for pages := 0; ; pages++ {
+		var err error
+		blocksRequest.PageToken = blocksResponse.NextPageToken
+		blocksResponse, err = pc.client.ListBlocks(context.Background(), blocksRequest)
 		if err != nil {
-			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %v",
-				a.Data.Slot/cfgSlotsPerEpoch, err)
+			return nil, pageError("blocks", epoch, pages, err)
 		}
-
-		a.Attesters = make([]uint64, 0)
-		for i := uint64(0); i < aggregationBits.Len(); i++ {
-			if aggregationBits.BitAt(i) {
-				validator, found := assignments.ValidatorAt(a.Data.Slot, a.Data.CommitteeIndex, i)
-				if !found { // This should never happen!
-					validator = 0
-					logger.Errorf("error retrieving assigned validator for attestation %v of block %v for slot %v committee index %v member index %v", i, b.Slot, a.Data.Slot, a.Data.CommitteeIndex, i)
-				}
-				a.Attesters = append(a.Attesters, validator)
-			}
+		for _, block := range blocksResponse.BlockContainers {
+			blocks = append(blocks, &types.MinimalBlock{
+				Epoch:      epoch,
+				Slot:       uint64(block.Block.Block.Slot),
+				BlockRoot:  block.BlockRoot,
+				ParentRoot: block.Block.Block.ParentRoot,
+				Canonical:  block.Canonical,
+			})
 		}
-
-		b.Attestations[i] = a
-	}
-	for i, deposit := range block.Block.Block.Body.Deposits {
-		b.Deposits[i] = &types.Deposit{
-			Proof:                 deposit.Proof,
-			PublicKey:             deposit.Data.PublicKey,
-			WithdrawalCredentials: deposit.Data.WithdrawalCredentials,
-			Amount:                deposit.Data.Amount,
-			Signature:             deposit.Data.Signature,
+		if blocksResponse.NextPageToken == "" {
+			break
 		}
 	}
+	return blocks, nil
+}
+
+// InvalidateEpoch drops the cached data of the epoch, in memory and in the files
+func (pc *PrysmClient) InvalidateEpoch(epoch uint64) error {
+	pc.assignmentsCache.Remove(epoch)
+	return InvalidateEpoch(epoch)
+}
 
-	for i, voluntaryExit := range block.Block.Block.Body.VoluntaryExits {
-		b.VoluntaryExits[i] = &types.VoluntaryExit{
-			Epoch:          uint64(voluntaryExit.Exit.Epoch),
-			ValidatorIndex: uint64(voluntaryExit.Exit.ValidatorIndex),
-			Signature:      voluntaryExit.Signature,
+func (pc *PrysmClient) parseRpcBlock(block *ethpb.BeaconBlockContainer) (*types.Block, error) {
+	b := types.NewBlockFromPB(block)
+	for _, a := range b.Attestations {
+		assignments, err := pc.GetEpochAssignments(a.Data.Slot / types.SlotsPerEpoch)
+		if err != nil {
+			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %w",
+				a.Data.Slot/types.SlotsPerEpoch, err)
 		}
+		resolveAttesters(assignments, a, b.Slot)
 	}
 	return b, nil
 }
 
-// GetValidatorParticipation will get the validator participation from Prysm client
+// GetValidatorParticipation will get the validator participation from the cache or from Prysm client,
+// the node has no participation of the epochs which are not finished
 func (pc *PrysmClient) GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
+	if HasParticipation(epoch) && pc.usable(FnParticipation(epoch)) {
+		out, err := LoadParticipation(epoch)
+		if err == nil {
+			return out, nil
+		}
+		logger.Errorf("LoadParticipation failure: %v", err)
+	}
+
 	validatorParticipationRequest := &ethpb.GetValidatorParticipationRequest{QueryFilter: &ethpb.GetValidatorParticipationRequest_Epoch{Epoch: eth2types.Epoch(epoch)}}
 	if epoch == 0 {
 		validatorParticipationRequest.QueryFilter = &ethpb.GetValidatorParticipationRequest_Genesis{Genesis: true}
 	}
 	epochParticipationStatistics, err := pc.client.GetValidatorParticipation(context.Background(), validatorParticipationRequest)
 	if err != nil {
-		logger.Printf("error retrieving epoch participation statistics: %v", err)
-		return &types.ValidatorParticipation{
-			Epoch:                   epoch,
-			Finalized:               false,
-			GlobalParticipationRate: 0,
-			VotedEther:              0,
-			EligibleEther:           0,
-		}, nil
-	}
-	return &types.ValidatorParticipation{
+		return nil, err
+	}
+	out := &types.ValidatorParticipation{
 		Epoch:                   epoch,
 		Finalized:               epochParticipationStatistics.Finalized,
 		GlobalParticipationRate: epochParticipationStatistics.Participation.GlobalParticipationRate,
 		VotedEther:              epochParticipationStatistics.Participation.VotedEther,
 		EligibleEther:           epochParticipationStatistics.Participation.EligibleEther,
-	}, nil
+	}
+	if err := SaveParticipation(epoch, out); err != nil {
+		logger.Errorf("SaveParticipation failure: %v", err)
+	}
+	pc.saved(FnParticipation(epoch), epoch)
+	return out, nil
+}
+
+// resolveAttesters fills the attesters of the attestation from its aggregation bits
+func resolveAttesters(assignments *types.Assignments, a *types.Attestation, blockSlot uint64) {
+	aggregationBits := bitfield.Bitlist(a.AggregationBits)
+	a.Attesters = make([]uint64, 0)
+	for i := uint64(0); i < aggregationBits.Len(); i++ {
+		if aggregationBits.BitAt(i) {
+			validator, found := assignments.ValidatorAt(a.Data.Slot, a.Data.CommitteeIndex, i)
+			if !found { // This should never happen!
+				validator = 0
+				logger.Errorf("error retrieving assigned validator for attestation %v of block %v for slot %v committee index %v member index %v", i, blockSlot, a.Data.Slot, a.Data.CommitteeIndex, i)
+			}
+			a.Attesters = append(a.Attesters, validator)
+		}
+	}
 }
@@ -0,0 +1,284 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newFakeClient starts the fake node with the synthetic chain
+// and connects to it with a fresh cache folder, like rpctest.NewSource
+func newFakeClient(t *testing.T, cfg fakenode.Config) (*PrysmClient, *fakenode.Node) {
+	SetCacheDir(t.TempDir())
+	node := fakenode.StartChain(cfg)
+	t.Cleanup(node.Close)
+
+	client, err := NewPrysmClient(fakenode.Endpoint, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client, node
+}
+
+func TestPrysmClientChainHead(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 64, Epochs: 4, GenesisTime: 1606824023})
+
+	genesis, err := client.GetGenesisTimestamp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genesis != 1606824023 {
+		t.Errorf("genesis time %v", genesis)
+	}
+
+	head, err := client.GetChainHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.HeadSlot != node.Chain.HeadSlot() || head.HeadEpoch != 3 || head.FinalizedEpoch != 1 || head.JustifiedEpoch != 2 {
+		t.Errorf("unexpected head %+v", head)
+	}
+}
+
+func TestPrysmClientAssignments(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 300, Epochs: 3, PageSize: 64})
+
+	for epoch := uint64(0); epoch < 3; epoch++ {
+		assignments, err := client.GetEpochAssignments(epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assignments.FirstSlot != epoch*32 || len(assignments.Assignments) != 32 || assignments.NumAssignments != 300 {
+			t.Fatalf("epoch %d: unexpected layout %+v", epoch, assignments)
+		}
+		for slotIndex, committees := range node.Chain.Committees(epoch) {
+			slot := epoch*32 + uint64(slotIndex)
+			if proposer := assignments.Assignments[slotIndex].Proposer; proposer != node.Chain.Proposer(slot) {
+				t.Errorf("slot %d: proposer %v, expected %v", slot, proposer, node.Chain.Proposer(slot))
+			}
+			if !reflect.DeepEqual(assignments.Assignments[slotIndex].Committees, committees) {
+				t.Errorf("slot %d: committees %v, expected %v", slot, assignments.Assignments[slotIndex].Committees, committees)
+			}
+		}
+		if !HasAssignments(epoch) && epoch > 0 {
+			t.Errorf("epoch %d: assignments are not cached", epoch)
+		}
+	}
+	if calls := node.Calls("ListValidatorAssignments"); calls != 15 {
+		t.Errorf("expected 5 pages per epoch, got %d calls", calls)
+	}
+
+	// the second request is served from memory
+	if _, err := client.GetEpochAssignments(2); err != nil {
+		t.Fatal(err)
+	}
+	if calls := node.Calls("ListValidatorAssignments"); calls != 15 {
+		t.Errorf("cached assignments were requested again, %d calls", calls)
+	}
+}
+
+func TestPrysmClientValidators(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{
+		Validators: 1000,
+		Epochs:     4,
+		PageSize:   300,
+		Slashings:  map[uint64]uint64{40: 7},
+		Deposits:   []uint64{50, 51},
+	})
+
+	balances, err := client.GetBalancesForEpoch(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1002 {
+		t.Fatalf("expected 1002 balances, got %d", len(balances))
+	}
+	for index, balance := range balances {
+		if expected, _ := node.Chain.Balance(index, 3); balance != expected {
+			t.Errorf("validator %d: balance %d, expected %d", index, balance, expected)
+		}
+	}
+
+	validators, err := client.GetEpochValidators(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validators) != 1002 {
+		t.Fatalf("expected 1002 validators, got %d", len(validators))
+	}
+	if !validators[7].Slashed || validators[8].Slashed {
+		t.Errorf("slashing of validator 7 is not reflected")
+	}
+	if v := validators[1001]; v.ActivationEpoch != 5 || v.ActivationEligibilityEpoch != 2 {
+		t.Errorf("deposited validator %+v", v)
+	}
+	if v := validators[20]; v.Balance != balances[20] || v.Balance1d != 32000000020 {
+		t.Errorf("balances of validator %+v", v)
+	}
+	if validators[7].Status != types.StatusActiveSlashed || validators[20].Status != types.StatusActiveOngoing {
+		t.Errorf("statuses %v and %v", validators[7].Status, validators[20].Status)
+	}
+	if validators[1001].Status != types.StatusPendingQueued {
+		t.Errorf("status of the deposited validator %v", validators[1001].Status)
+	}
+
+	cached, err := LoadValidators(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 1002 || cached[7].Index != 7 || !cached[7].Slashed || cached[7].Status != types.StatusActiveSlashed {
+		t.Errorf("unexpected cached validators")
+	}
+}
+
+func TestPrysmClientEpochData(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{
+		Validators:    256,
+		Epochs:        4,
+		PageSize:      100,
+		MissedSlots:   []uint64{33, 34},
+		OrphanedSlots: []uint64{40},
+		Slashings:     map[uint64]uint64{45: 3},
+	})
+
+	data, err := client.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Blocks) != 32 {
+		t.Fatalf("expected 32 slots, got %d", len(data.Blocks))
+	}
+	for _, slot := range []uint64{33, 34} {
+		missed, ok := data.Blocks[slot]["0x0"]
+		if !ok || missed.Status != 2 || missed.Proposer != node.Chain.Proposer(slot) {
+			t.Errorf("slot %d is not marked as missed: %+v", slot, data.Blocks[slot])
+		}
+	}
+	if len(data.Blocks[40]) != 2 {
+		t.Errorf("expected canonical and orphaned blocks at slot 40, got %d", len(data.Blocks[40]))
+	}
+	for slot, status := range map[uint64]types.ProposalStatus{33: types.ProposalMissed, 40: types.ProposalProposed, 41: types.ProposalProposed} {
+		if p, ok := data.Proposals.Get(slot); !ok || p.Status != status {
+			t.Errorf("slot %d: proposal %+v, expected %v", slot, p, status)
+		}
+	}
+
+	// block at 35 includes the attestations of the slots 32-34
+	var block *types.Block
+	for _, b := range data.Blocks[35] {
+		block = b
+	}
+	if block == nil || len(block.Attestations) != 3 {
+		t.Fatalf("unexpected block at slot 35: %+v", data.Blocks[35])
+	}
+	for _, a := range block.Attestations {
+		expected := node.Chain.Committees(1)[a.Data.Slot-32][a.Data.CommitteeIndex]
+		if !reflect.DeepEqual(a.Attesters, expected) {
+			t.Errorf("attesters of slot %d: %v, expected %v", a.Data.Slot, a.Attesters, expected)
+		}
+	}
+
+	for _, b := range data.Blocks[45] {
+		if len(b.ProposerSlashings) != 1 || b.ProposerSlashings[0].ProposerIndex != 3 {
+			t.Errorf("unexpected proposer slashings %+v", b.ProposerSlashings)
+		}
+	}
+	if len(data.Validators) != 256 {
+		t.Errorf("expected 256 validators, got %d", len(data.Validators))
+	}
+	if p := data.EpochParticipationStats; p == nil || p.GlobalParticipationRate != 1 || !p.Finalized {
+		t.Errorf("unexpected participation %+v", p)
+	}
+}
+
+func TestPrysmClientConcurrentCallsShareFetch(t *testing.T) {
+	client, node := newFakeClient(t, fakenode.Config{Validators: 1000, Epochs: 3, PageSize: 250})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(epoch uint64) {
+			defer wg.Done()
+			if _, err := client.GetEpochAssignments(epoch); err != nil {
+				t.Error(err)
+			}
+			if _, err := client.GetEpochValidators(2); err != nil {
+				t.Error(err)
+			}
+		}(uint64(1 + i%2))
+	}
+	wg.Wait()
+
+	if calls := node.Calls("ListValidatorAssignments"); calls != 8 {
+		t.Errorf("expected 4 pages for each of 2 epochs, got %d calls", calls)
+	}
+	if calls := node.Calls("ListValidators"); calls != 4 {
+		t.Errorf("expected 4 pages of validators, got %d calls", calls)
+	}
+}
+
+func TestPrysmClientParallelEpochData(t *testing.T) {
+	cfg := fakenode.Config{Validators: 1000, Epochs: 3, PageSize: 250, MissedSlots: []uint64{40}, OrphanedSlots: []uint64{50}}
+	sequential, _ := newFakeClient(t, cfg)
+	sequential.SetParallelism(1)
+	expected, err := sequential.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, node := newFakeClient(t, cfg)
+	client.SetParallelism(8)
+	data, err := client.GetEpochData(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected.Blocks, data.Blocks) {
+		t.Error("blocks mismatch between sequential and parallel fetching")
+	}
+	if !reflect.DeepEqual(expected.Validators, data.Validators) {
+		t.Error("validators mismatch between sequential and parallel fetching")
+	}
+	if calls := node.Calls("ListBlocks"); calls != 32 {
+		t.Errorf("expected a request per slot, got %d calls", calls)
+	}
+	// all lookback epochs are before genesis, it is requested once
+	if calls := node.Calls("ListValidatorBalances"); calls != 8 {
+		t.Errorf("expected 4 pages for epochs 0 and 1, got %d calls", calls)
+	}
+}
+
+func TestParallelStopsOnError(t *testing.T) {
+	var mux sync.Mutex
+	running, maxRunning, started := 0, 0, 0
+	err := parallel(3, 100, func(i int) error {
+		mux.Lock()
+		running++
+		started++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mux.Unlock()
+		defer func() {
+			mux.Lock()
+			running--
+			mux.Unlock()
+		}()
+		if i == 10 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if maxRunning > 3 {
+		t.Errorf("%d calls were running at once, limit is 3", maxRunning)
+	}
+	if started == 100 {
+		t.Error("calls were started after the failure")
+	}
+}
@@ -0,0 +1,33 @@
+// Package rpctest connects the tests to the fake beacon node with a fresh cache folder
+package rpctest
+
+import (
+	"beaconchain/rpc"
+	"beaconchain/rpc/fakenode"
+	"testing"
+)
+
+// NewTestNode serves the chain, generated from the configuration, till the end of the test
+func NewTestNode(t testing.TB, cfg fakenode.Config) *fakenode.Node {
+	node := fakenode.StartChain(cfg)
+	t.Cleanup(node.Close)
+	return node
+}
+
+// NewNode serves the fake chain like NewTestNode, the cache folder is a new temporary one
+func NewNode(t testing.TB, cfg fakenode.Config) *fakenode.Node {
+	rpc.SetCacheDir(t.TempDir())
+	return NewTestNode(t, cfg)
+}
+
+// NewSource serves the fake chain like NewNode and connects to it with the prysm client,
+// which is closed at the end of the test
+func NewSource(t testing.TB, cfg fakenode.Config) (*rpc.PrysmClient, *fakenode.Node) {
+	node := NewNode(t, cfg)
+	client, err := rpc.NewPrysmClient(fakenode.Endpoint, node.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client, node
+}
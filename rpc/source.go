@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// BeaconSource is a backend-agnostic access to the beacon chain data
+type BeaconSource interface {
+	// GetGenesisTimestamp returns the genesis timestamp of the beacon chain
+	GetGenesisTimestamp() (int64, error)
+	// GetChainHead returns the current head, justified and finalized checkpoints
+	GetChainHead() (*types.ChainHead, error)
+	// GetBlocksBySlot returns all blocks known at the slot, canonical or not
+	GetBlocksBySlot(slot uint64) ([]*types.Block, error)
+	// GetEpochBlocks returns all blocks of the epoch, canonical or not
+	GetEpochBlocks(epoch uint64) ([]*types.Block, error)
+	// GetEpochValidators returns the validator set with balances of the epoch
+	GetEpochValidators(epoch uint64) ([]*types.Validator, error)
+	// GetBalancesForEpoch returns map of validator index to its balance at the epoch
+	GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error)
+	// GetEpochAssignments returns proposers and committees of the epoch
+	GetEpochAssignments(epoch uint64) (*types.Assignments, error)
+	// GetValidatorParticipation returns participation statistics of the epoch
+	GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error)
+	// SetParallelism limits the number of concurrent requests to the node
+	SetParallelism(limit int)
+	// SetAcceptProvisional tells whether data cached before the finalization of its epoch is accepted
+	SetAcceptProvisional(accept bool)
+	// SetLookbacks changes the windows of the balance history of the validators
+	SetLookbacks(windows []types.Lookback)
+	// Close releases the connection to the node
+	Close()
+}
+
+var _ BeaconSource = (*PrysmClient)(nil)
+var _ BeaconSource = (*RestClient)(nil)
+
+// parseHost splits the host into its address and options,
+// i.e. localhost:4000?duties=http://localhost:3500
+func parseHost(host string) (string, url.Values, error) {
+	parts := strings.SplitN(host, "?", 2)
+	if len(parts) == 1 {
+		return host, url.Values{}, nil
+	}
+	params, err := url.ParseQuery(parts[1])
+	if err != nil {
+		return "", nil, fmt.Errorf("options of host %v: %w", parts[0], err)
+	}
+	return parts[0], params, nil
+}
+
+// NewSource connects to the host using the selected API,
+// dial options are applied to gRPC connections only.
+// The gRPC host with the duties option builds the assignments from the beacon committees
+// and the proposer duties of the standard API at that URL, the mixes option computes them
+// from the RANDAO mixes of the standard API at that URL
+func NewSource(api string, host string, opts ...grpc.DialOption) (BeaconSource, error) {
+	address, params, err := parseHost(host)
+	if err != nil {
+		return nil, err
+	}
+	switch api {
+	case "prysm":
+		client, err := NewPrysmClient(address, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if duties := params.Get("duties"); duties != "" {
+			rest, err := NewRestClient(duties)
+			if err != nil {
+				client.Close()
+				return nil, err
+			}
+			client.SetProposerDuties(rest)
+		}
+		if mixes := params.Get("mixes"); mixes != "" {
+			rest, err := NewRestClient(mixes)
+			if err != nil {
+				client.Close()
+				return nil, err
+			}
+			client.SetRandaoMixes(rest)
+		}
+		return client, nil
+	case "rest":
+		return NewRestClient(address)
+	case "cache":
+		return NewCacheSource(), nil
+	}
+	return nil, fmt.Errorf("unknown API %q, expected prysm, rest or cache", api)
+}
+
+// EpochRange resolves the range of the epochs, the last epoch defaults to the head epoch
+// and the first one to the given number of epochs before the last one
+func EpochRange(source BeaconSource, first, last int64, epochs uint64) (uint64, uint64, error) {
+	if last < 0 {
+		head, err := source.GetChainHead()
+		if err != nil {
+			return 0, 0, err
+		}
+		last = int64(head.HeadEpoch)
+	}
+	if first < 0 {
+		first = last - int64(epochs) + 1
+		if first < 0 {
+			first = 0
+		}
+	}
+	if first > last {
+		return 0, 0, fmt.Errorf("empty range of epochs %d-%d", first, last)
+	}
+	return uint64(first), uint64(last), nil
+}
@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"beaconchain/types"
+	"bytes"
+	"fmt"
+	"time"
+
+	empty "github.com/golang/protobuf/ptypes/empty"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// streamReconnectDelay is the pause before reopening the failed stream
+var streamReconnectDelay = 5 * time.Second
+
+// Subscribe starts streaming of the new blocks into GetNewBlockChan and of the chain heads into GetChainHeadChan.
+// Blocks after the since slot are published, 0 starts from the current head.
+// Failed streams are reopened, the slots missed meanwhile are backfilled. Streams are stopped by Close
+func (pc *PrysmClient) Subscribe(since uint64) {
+	pc.subscribeOnce.Do(func() {
+		pc.lastSlot = since
+		pc.streams.Add(2)
+		go pc.keepStreaming("blocks", pc.streamBlocks)
+		go pc.keepStreaming("chain head", pc.streamChainHead)
+	})
+}
+
+// GetChainHeadChan returns the channel of the streamed chain heads
+func (pc *PrysmClient) GetChainHeadChan() chan *types.ChainHead {
+	return pc.chainHeadChan
+}
+
+// keepStreaming reopens the stream until the client is closed
+func (pc *PrysmClient) keepStreaming(name string, stream func() error) {
+	defer pc.streams.Done()
+	for {
+		err := stream()
+		if pc.ctx.Err() != nil {
+			return
+		}
+		logger.Errorf("%v stream failure: %v, reconnecting in %v", name, err, streamReconnectDelay)
+		select {
+		case <-pc.ctx.Done():
+			return
+		case <-time.After(streamReconnectDelay):
+		}
+	}
+}
+
+// streamBlocks publishes the blocks missed since the last one and then the streamed ones
+func (pc *PrysmClient) streamBlocks() error {
+	// the stream is opened before the backfill, so no block falls in between
+	stream, err := pc.client.StreamBlocks(pc.ctx, &ethpb.StreamBlocksRequest{VerifiedOnly: true})
+	if err != nil {
+		return err
+	}
+	if err := pc.backfill(); err != nil {
+		return err
+	}
+	for {
+		signed, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		root, err := signed.Block.HashTreeRoot()
+		if err != nil {
+			return fmt.Errorf("error calculating root of the block at slot %v: %w", signed.Block.Slot, err)
+		}
+		// the stream sends the blocks of the forks as well
+		canonical, err := pc.isCanonical(root[:])
+		if err != nil {
+			return err
+		}
+		block, err := pc.parseRpcBlock(&ethpb.BeaconBlockContainer{
+			Block:     signed,
+			BlockRoot: root[:],
+			Canonical: canonical,
+		})
+		if err != nil {
+			return err
+		}
+		pc.publishBlock(block)
+	}
+}
+
+// isCanonical asks the node whether the block of the root is canonical, false for the unknown one
+func (pc *PrysmClient) isCanonical(root []byte) (bool, error) {
+	res, err := pc.client.ListBlocks(pc.ctx, &ethpb.ListBlocksRequest{QueryFilter: &ethpb.ListBlocksRequest_Root{Root: root}})
+	if err != nil {
+		return false, err
+	}
+	for _, container := range res.BlockContainers {
+		if bytes.Equal(container.BlockRoot, root) {
+			return container.Canonical, nil
+		}
+	}
+	return false, nil
+}
+
+// backfill publishes the blocks of the slots between the last published one and the head
+func (pc *PrysmClient) backfill() error {
+	head, err := pc.GetChainHead()
+	if err != nil {
+		return err
+	}
+	if pc.lastSlot == 0 {
+		pc.lastSlot = head.HeadSlot
+		return nil
+	}
+	if head.HeadSlot > pc.lastSlot {
+		logger.Infof("backfilling slots %v-%v", pc.lastSlot+1, head.HeadSlot)
+	}
+	for slot := pc.lastSlot + 1; slot <= head.HeadSlot; slot++ {
+		blocks, err := pc.GetBlocksBySlot(slot)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			pc.publishBlock(block)
+		}
+	}
+	if head.HeadSlot > pc.lastSlot {
+		pc.lastSlot = head.HeadSlot
+	}
+	return nil
+}
+
+// publishBlock sends the block to the channel unless it was already published
+func (pc *PrysmClient) publishBlock(block *types.Block) {
+	root := fmt.Sprintf("%x", block.BlockRoot)
+	if found, _ := pc.publishedRoots.ContainsOrAdd(root, true); found {
+		return
+	}
+	if block.Slot > pc.lastSlot {
+		pc.lastSlot = block.Slot
+	}
+	select {
+	case pc.newBlockChan <- block:
+	case <-pc.ctx.Done():
+	}
+}
+
+// streamChainHead publishes the streamed chain heads
+func (pc *PrysmClient) streamChainHead() error {
+	stream, err := pc.client.StreamChainHead(pc.ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
+	for {
+		head, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		select {
+		case pc.chainHeadChan <- newChainHead(head):
+		case <-pc.ctx.Done():
+			return pc.ctx.Err()
+		}
+	}
+}
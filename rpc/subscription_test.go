@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"beaconchain/rpc/fakenode"
+	"beaconchain/types"
+	"testing"
+	"time"
+)
+
+// waitFor fails the test if the condition is not met in a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %v", what)
+		}
+	}
+}
+
+// receiveBlocks reads n blocks from the channel
+func receiveBlocks(t *testing.T, ch chan *types.Block, n int) []*types.Block {
+	t.Helper()
+	res := make([]*types.Block, 0, n)
+	for len(res) < n {
+		select {
+		case b := <-ch:
+			res = append(res, b)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d blocks of %d", len(res), n)
+		}
+	}
+	return res
+}
+
+func TestPrysmClientSubscribe(t *testing.T) {
+	defer func(delay time.Duration) { streamReconnectDelay = delay }(streamReconnectDelay)
+	streamReconnectDelay = 200 * time.Millisecond
+
+	client, node := newFakeClient(t, fakenode.Config{Validators: 256, Epochs: 2, OrphanedSlots: []uint64{65}})
+	client.Subscribe(0)
+	waitFor(t, "streams", func() bool {
+		return node.Streams() == 2 && node.Calls("GetChainHead") == 1
+	})
+
+	node.Advance(2)
+	blocks := receiveBlocks(t, client.GetNewBlockChan(), 3)
+	if blocks[0].Slot != 64 || blocks[1].Slot != 65 || blocks[2].Slot != 65 {
+		t.Errorf("unexpected slots %d, %d, %d", blocks[0].Slot, blocks[1].Slot, blocks[2].Slot)
+	}
+	if blocks[0].Proposer != node.Chain.Proposer(64) || len(blocks[0].Attestations) == 0 {
+		t.Errorf("block is not parsed %+v", blocks[0])
+	}
+	// the block of the fork is published as orphaned
+	if !blocks[0].Canonical || !blocks[1].Canonical || blocks[2].Canonical || blocks[2].Status != types.BlockOrphaned {
+		t.Errorf("canonical flags %v, %v, %v", blocks[0].Canonical, blocks[1].Canonical, blocks[2].Canonical)
+	}
+	// the head is sent after each slot, it could be already advanced further
+	for headSlot := uint64(0); headSlot != 65; {
+		select {
+		case head := <-client.GetChainHeadChan():
+			if head.HeadSlot < 64 || head.HeadSlot < headSlot {
+				t.Fatalf("unexpected head slot %d", head.HeadSlot)
+			}
+			headSlot = head.HeadSlot
+		case <-time.After(5 * time.Second):
+			t.Fatal("no chain head")
+		}
+	}
+
+	// blocks produced while the streams are down are backfilled after the reconnect
+	node.DropStreams()
+	node.Advance(3)
+	blocks = receiveBlocks(t, client.GetNewBlockChan(), 3)
+	for i, b := range blocks {
+		if b.Slot != uint64(66+i) {
+			t.Errorf("backfilled block %d at slot %d", i, b.Slot)
+		}
+	}
+	if calls := node.Calls("StreamBlocks"); calls != 2 {
+		t.Errorf("expected reconnect of the blocks stream, got %d calls", calls)
+	}
+
+	node.Advance(1)
+	if b := receiveBlocks(t, client.GetNewBlockChan(), 1)[0]; b.Slot != 69 {
+		t.Errorf("streamed block at slot %d after the reconnect", b.Slot)
+	}
+
+	client.Close()
+	for range client.GetNewBlockChan() {
+	}
+	for range client.GetChainHeadChan() {
+	}
+}
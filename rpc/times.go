@@ -1,20 +1,21 @@
 package rpc
 
-import "time"
+import (
+	"beaconchain/types"
+	"time"
+)
 
 const cfgPageSize = 50000
-const cfgSlotsPerEpoch = 32
-const cfgSecondsPerSlot = 12
 const cfgGenesisTimestamp = 1573489682
 
 // EpochOfSlot will return the corresponding epoch of a slot
 func EpochOfSlot(slot uint64) uint64 {
-	return slot / cfgSlotsPerEpoch
+	return slot / types.SlotsPerEpoch
 }
 
 // SlotToTime will return a time.Time to slot
 func SlotToTime(slot uint64) time.Time {
-	return time.Unix(int64(cfgGenesisTimestamp+slot*cfgSecondsPerSlot), 0)
+	return time.Unix(int64(cfgGenesisTimestamp+slot*types.SecondsPerSlot), 0)
 }
 
 // TimeToSlot will return time to slot in seconds
@@ -22,12 +23,12 @@ func TimeToSlot(timestamp uint64) uint64 {
 	if cfgGenesisTimestamp > timestamp {
 		return 0
 	}
-	return (timestamp - cfgGenesisTimestamp) / cfgSecondsPerSlot
+	return (timestamp - cfgGenesisTimestamp) / types.SecondsPerSlot
 }
 
 // EpochToTime will return a time.Time for an epoch
 func EpochToTime(epoch uint64) time.Time {
-	return time.Unix(int64(cfgGenesisTimestamp+epoch*cfgSecondsPerSlot*cfgSlotsPerEpoch), 0)
+	return time.Unix(int64(cfgGenesisTimestamp+epoch*types.SecondsPerSlot*types.SlotsPerEpoch), 0)
 }
 
 // EpochToTime will return a time.Time for an epoch
@@ -40,5 +41,5 @@ func TimeToEpoch(ts time.Time) int64 {
 	if int64(cfgGenesisTimestamp) > ts.Unix() {
 		return 0
 	}
-	return (ts.Unix() - int64(cfgGenesisTimestamp)) / int64(cfgSecondsPerSlot) / int64(cfgSlotsPerEpoch)
+	return (ts.Unix() - int64(cfgGenesisTimestamp)) / int64(types.SecondsPerSlot) / int64(types.SlotsPerEpoch)
 }
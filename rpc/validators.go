@@ -7,7 +7,6 @@ import (
 	"compress/gzip"
 	"encoding/binary"
 	"encoding/gob"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -19,7 +18,7 @@ import (
 var logvalidators = logrus.New().WithField("module", "validators")
 
 func FnValidators(epoch uint64) string {
-	return fmt.Sprintf("/cache/%d.validators.gz", epoch)
+	return CachePath(fmt.Sprintf("%d.validators.gz", epoch))
 }
 
 func HasValidators(epoch uint64) bool {
@@ -42,7 +41,7 @@ func LoadValidators(epoch uint64) ([]types.ValidatorF, error) {
 	start := time.Now()
 	file, err := os.Open(FnValidators(epoch))
 	if err != nil {
-		return nil, err
+		return nil, cacheError(err, "validators", epoch)
 	}
 	defer file.Close()
 	stats, statsErr := file.Stat()
@@ -52,7 +51,7 @@ func LoadValidators(epoch uint64) ([]types.ValidatorF, error) {
 
 	var size int64 = stats.Size()
 	if size <= 255 {
-		return nil, errors.New("empty storage")
+		return nil, emptyStorage(FnValidators(epoch))
 	}
 	file, err = os.Open(FnValidators(epoch))
 	if err != nil {
@@ -78,6 +77,10 @@ func LoadValidators(epoch uint64) ([]types.ValidatorF, error) {
 	if err != nil {
 		return nil, err
 	}
+	// files cached before the status was derived have none
+	for i := range out {
+		out[i].Status = out[i].StatusAt(epoch)
+	}
 	logvalidators.Infof("%d validators loaded from cache of epoch %d within %v", len(out), epoch, time.Since(start))
 	return out, nil
 }
@@ -0,0 +1,55 @@
+package shuffle
+
+import "beaconchain/types"
+
+// ComputeAssignments returns committees and proposers of the epoch from the RANDAO mix the seeds
+// are derived from, sorted indices of the active validators and their effective balances in Gwei
+func ComputeAssignments(epoch uint64, mix [32]byte, active []uint64, effectiveBalances map[uint64]uint64) *types.Assignments {
+	firstSlot := epoch * types.SlotsPerEpoch
+	total := uint64(len(active))
+	perSlot := CommitteeCountPerSlot(total)
+	count := perSlot * types.SlotsPerEpoch
+
+	attesterSeed := Seed(mix, epoch, DomainBeaconAttester)
+	shuffled := make([]uint64, total)
+	for i, p := range ShuffledIndices(total, attesterSeed) {
+		shuffled[i] = active[p]
+	}
+	proposerSeed := Seed(mix, epoch, DomainBeaconProposer)
+	effectiveBalance := func(index uint64) uint64 { return effectiveBalances[index] }
+
+	numAssignments := 0
+	assignments := make([]types.AssignmentSlot, types.SlotsPerEpoch)
+	for slotIndex := range assignments {
+		slot := firstSlot + uint64(slotIndex)
+		committees := make([][]uint64, 0, perSlot)
+		for committeeIndex := uint64(0); committeeIndex < perSlot; committeeIndex++ {
+			k := uint64(slotIndex)*perSlot + committeeIndex
+			members := shuffled[total*k/count : total*(k+1)/count]
+			if len(members) == 0 {
+				members = nil
+			}
+			committees = append(committees, members)
+		}
+		// empty committees have no assignments, like the ones of the node
+		for len(committees) > 0 && committees[len(committees)-1] == nil {
+			committees = committees[:len(committees)-1]
+		}
+		for _, members := range committees {
+			numAssignments += len(members)
+		}
+		assignments[slotIndex] = types.AssignmentSlot{
+			Proposer:   ProposerIndex(active, ProposerSeed(proposerSeed, slot), effectiveBalance),
+			Committees: committees,
+		}
+	}
+	out := &types.Assignments{
+		Epoch:          uint32(epoch),
+		FirstSlot:      firstSlot,
+		NumSlots:       types.SlotsPerEpoch,
+		NumAssignments: uint64(numAssignments),
+		Assignments:    assignments,
+	}
+	out.SetDuties(types.NewDutyIndex(out))
+	return out
+}
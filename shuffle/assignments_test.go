@@ -0,0 +1,76 @@
+package shuffle
+
+import (
+	"beaconchain/types"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// registry returns the active indices and the effective balances of the registry of assignments.csv
+func registry(n uint64) ([]uint64, map[uint64]uint64) {
+	active := make([]uint64, 0, n)
+	balances := make(map[uint64]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		switch {
+		case i%7 == 0:
+			balances[i] = 16 * 1000000000
+		case i%5 == 0:
+			balances[i] = 31 * 1000000000
+		default:
+			balances[i] = MaxEffectiveBalance
+		}
+		if i%11 != 3 {
+			active = append(active, i)
+		}
+	}
+	return active, balances
+}
+
+func TestComputeAssignmentsMatchRecorded(t *testing.T) {
+	slots := make(map[uint64][][]string)
+	epochs := make([]uint64, 0)
+	for _, v := range readVectors(t, "assignments.csv") {
+		epoch, _ := strconv.ParseUint(v[0], 10, 64)
+		if _, ok := slots[epoch]; !ok {
+			epochs = append(epochs, epoch)
+		}
+		slots[epoch] = append(slots[epoch], v)
+	}
+	if len(epochs) != 3 {
+		t.Fatalf("unexpected epochs %v", epochs)
+	}
+
+	for _, epoch := range epochs {
+		rows := slots[epoch]
+		n, _ := strconv.ParseUint(rows[0][1], 10, 64)
+		active, balances := registry(n)
+		computed := ComputeAssignments(epoch, parseSeed(t, rows[0][2]), active, balances)
+		if len(rows) != types.SlotsPerEpoch || computed.NumAssignments != uint64(len(active)) {
+			t.Fatalf("epoch %d: %d slots, %d assignments of %d active", epoch, len(rows), computed.NumAssignments, len(active))
+		}
+		for i, v := range rows {
+			slot, _ := strconv.ParseUint(v[3], 10, 64)
+			proposer, _ := strconv.ParseUint(v[4], 10, 64)
+			assignment := computed.Assignments[i]
+			if computed.FirstSlot+uint64(i) != slot || assignment.Proposer != proposer {
+				t.Errorf("slot %d: proposer %d, expected %d", slot, assignment.Proposer, proposer)
+			}
+			// the node leaves out the empty committees at the end of the slot
+			committees := make([][]uint64, 0)
+			for _, field := range v[5:] {
+				if members := parseList(t, field); len(members) > 0 {
+					committees = append(committees, members)
+				} else {
+					committees = append(committees, nil)
+				}
+			}
+			for len(committees) > 0 && committees[len(committees)-1] == nil {
+				committees = committees[:len(committees)-1]
+			}
+			if !reflect.DeepEqual(assignment.Committees, committees) {
+				t.Errorf("slot %d: committees %v, expected %v", slot, assignment.Committees, committees)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package shuffle computes committees and proposers of the epoch the way the beacon chain does,
+// from the RANDAO mix and the active validator set, without asking the node
+package shuffle
+
+import (
+	"beaconchain/types"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+const (
+	// ShuffleRoundCount is the number of rounds of the swap-or-not shuffle
+	ShuffleRoundCount = 90
+	// TargetCommitteeSize is the number of validators in the committee, when there are enough of them
+	TargetCommitteeSize = 128
+	// MaxCommitteesPerSlot limits the number of committees in the slot
+	MaxCommitteesPerSlot = 64
+	// MaxEffectiveBalance is the effective balance of 32 ETH in Gwei
+	MaxEffectiveBalance = 32 * 1000000000
+)
+
+// DomainBeaconProposer and DomainBeaconAttester are the domains of the seeds
+var (
+	DomainBeaconProposer = [4]byte{0, 0, 0, 0}
+	DomainBeaconAttester = [4]byte{1, 0, 0, 0}
+)
+
+// Seed returns the seed of the epoch for the domain, the mix is the RANDAO mix
+// of the epoch MIN_SEED_LOOKAHEAD+1 epochs before it
+func Seed(mix [32]byte, epoch uint64, domain [4]byte) [32]byte {
+	buf := make([]byte, 4+8+32)
+	copy(buf, domain[:])
+	binary.LittleEndian.PutUint64(buf[4:], epoch)
+	copy(buf[12:], mix[:])
+	return sha256.Sum256(buf)
+}
+
+// ProposerSeed returns the seed of the proposer selection of the slot
+func ProposerSeed(proposerEpochSeed [32]byte, slot uint64) [32]byte {
+	buf := make([]byte, 32+8)
+	copy(buf, proposerEpochSeed[:])
+	binary.LittleEndian.PutUint64(buf[32:], slot)
+	return sha256.Sum256(buf)
+}
+
+// ShuffledIndex returns the position of the index in the permutation of count indices,
+// it follows compute_shuffled_index of the spec
+func ShuffledIndex(index uint64, count uint64, seed [32]byte) uint64 {
+	buf := make([]byte, 32+1+4)
+	copy(buf, seed[:])
+	for round := 0; round < ShuffleRoundCount; round++ {
+		buf[32] = byte(round)
+		h := sha256.Sum256(buf[:33])
+		pivot := binary.LittleEndian.Uint64(h[:8]) % count
+		flip := (pivot + count - index) % count
+		position := index
+		if flip > position {
+			position = flip
+		}
+		binary.LittleEndian.PutUint32(buf[33:], uint32(position/256))
+		source := sha256.Sum256(buf)
+		if (source[(position%256)/8]>>(position%8))&1 == 1 {
+			index = flip
+		}
+	}
+	return index
+}
+
+// ShuffledIndices returns ShuffledIndex of every index below count,
+// the hashes of every round are computed once for all indices
+func ShuffledIndices(count uint64, seed [32]byte) []uint64 {
+	res := make([]uint64, count)
+	for i := range res {
+		res[i] = uint64(i)
+	}
+	if count <= 1 {
+		return res
+	}
+	buf := make([]byte, 32+1+4)
+	copy(buf, seed[:])
+	sources := make([][32]byte, (count+255)/256)
+	for round := 0; round < ShuffleRoundCount; round++ {
+		buf[32] = byte(round)
+		h := sha256.Sum256(buf[:33])
+		pivot := binary.LittleEndian.Uint64(h[:8]) % count
+		for i := range sources {
+			binary.LittleEndian.PutUint32(buf[33:], uint32(i))
+			sources[i] = sha256.Sum256(buf)
+		}
+		for i, index := range res {
+			flip := (pivot + count - index) % count
+			position := index
+			if flip > position {
+				position = flip
+			}
+			if (sources[position/256][(position%256)/8]>>(position%8))&1 == 1 {
+				res[i] = flip
+			}
+		}
+	}
+	return res
+}
+
+// ComputeCommittee returns the committee of the index out of count committees of the indices,
+// it follows compute_committee of the spec
+func ComputeCommittee(indices []uint64, seed [32]byte, index uint64, count uint64) []uint64 {
+	total := uint64(len(indices))
+	start, end := total*index/count, total*(index+1)/count
+	res := make([]uint64, 0, end-start)
+	for i := start; i < end; i++ {
+		res = append(res, indices[ShuffledIndex(i, total, seed)])
+	}
+	return res
+}
+
+// CommitteeCountPerSlot returns the number of committees in every slot of the epoch
+// with the number of active validators
+func CommitteeCountPerSlot(active uint64) uint64 {
+	n := active / types.SlotsPerEpoch / TargetCommitteeSize
+	if n > MaxCommitteesPerSlot {
+		return MaxCommitteesPerSlot
+	}
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// ProposerIndex selects the proposer out of the active indices with the probability
+// proportional to the effective balance, it follows compute_proposer_index of the spec
+func ProposerIndex(indices []uint64, seed [32]byte, effectiveBalance func(index uint64) uint64) uint64 {
+	total := uint64(len(indices))
+	if total == 0 {
+		return 0
+	}
+	buf := make([]byte, 32+8)
+	copy(buf, seed[:])
+	var random [32]byte
+	for i := uint64(0); ; i++ {
+		candidate := indices[ShuffledIndex(i%total, total, seed)]
+		if i%32 == 0 {
+			binary.LittleEndian.PutUint64(buf[32:], i/32)
+			random = sha256.Sum256(buf)
+		}
+		if effectiveBalance(candidate)*255 >= MaxEffectiveBalance*uint64(random[i%32]) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package shuffle
+
+import (
+	"bufio"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readVectors returns the comma separated fields of the test vectors,
+// their lists are separated by colons
+func readVectors(t *testing.T, name string) [][]string {
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	res := make([][]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" && !strings.HasPrefix(line, "#") {
+			res = append(res, strings.Split(line, ","))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func parseSeed(t *testing.T, s string) [32]byte {
+	var seed [32]byte
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		t.Fatalf("seed %q: %v", s, err)
+	}
+	copy(seed[:], b)
+	return seed
+}
+
+func parseList(t *testing.T, s string) []uint64 {
+	res := make([]uint64, 0)
+	if s == "" {
+		return res
+	}
+	for _, item := range strings.Split(s, ":") {
+		n, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, n)
+	}
+	return res
+}
+
+func TestShuffledIndex(t *testing.T) {
+	for _, v := range readVectors(t, "shuffle_mapping.csv") {
+		seed := parseSeed(t, v[0])
+		count, _ := strconv.ParseUint(v[1], 10, 64)
+		expected := parseList(t, v[2])
+		for i := uint64(0); i < count; i++ {
+			if res := ShuffledIndex(i, count, seed); res != expected[i] {
+				t.Fatalf("seed %s, count %d: index %d shuffled to %d, expected %d", v[0], count, i, res, expected[i])
+			}
+		}
+		if res := ShuffledIndices(count, seed); !reflect.DeepEqual(res, expected) {
+			t.Errorf("seed %s, count %d: mapping %v, expected %v", v[0], count, res, expected)
+		}
+	}
+}
+
+func TestComputeCommittee(t *testing.T) {
+	seed := Seed([32]byte{7}, 3, DomainBeaconAttester)
+	indices := make([]uint64, 300)
+	for i := range indices {
+		indices[i] = uint64(i * 2)
+	}
+	mapping := ShuffledIndices(uint64(len(indices)), seed)
+	members := make(map[uint64]bool)
+	position := 0
+	for index := uint64(0); index < 7; index++ {
+		for _, member := range ComputeCommittee(indices, seed, index, 7) {
+			if member != indices[mapping[position]] || members[member] {
+				t.Fatalf("committee %d: unexpected member %d at %d", index, member, position)
+			}
+			members[member] = true
+			position++
+		}
+	}
+	if position != len(indices) {
+		t.Errorf("committees cover %d of %d indices", position, len(indices))
+	}
+}
+
+func TestProposerIndex(t *testing.T) {
+	for _, v := range readVectors(t, "proposer_index.csv") {
+		seed := parseSeed(t, v[0])
+		balances := parseList(t, v[1])
+		active := parseList(t, v[2])
+		expected, _ := strconv.ParseUint(v[3], 10, 64)
+		res := ProposerIndex(active, seed, func(index uint64) uint64 { return balances[index] * 1000000000 })
+		if res != expected {
+			t.Errorf("seed %s: proposer %d, expected %d", v[0], res, expected)
+		}
+	}
+}
+
+func TestCommitteeCountPerSlot(t *testing.T) {
+	for active, expected := range map[uint64]uint64{0: 1, 4095: 1, 8192: 2, 100000: 24, 1000000: 64} {
+		if res := CommitteeCountPerSlot(active); res != expected {
+			t.Errorf("%d active: %d committees, expected %d", active, res, expected)
+		}
+	}
+}
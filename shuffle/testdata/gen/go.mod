@@ -0,0 +1,5 @@
+module gen
+
+go 1.16
+
+require github.com/protolambda/eth2-shuffle v1.1.0
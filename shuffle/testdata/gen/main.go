@@ -0,0 +1,201 @@
+// Command gen writes the test vectors of the shuffle package with eth2-shuffle and the committee
+// and proposer selection of prysm v1.4.4 (beacon-chain/core/helpers), independently of the shuffle package:
+//
+//	go run .
+//
+// shuffle_mapping.csv follows the shuffling/core vectors of consensus-spec-tests: the seeds are
+// hash(uint_to_bytes4(i)) for i below 10, the mapping is compute_shuffled_index of every index below the count.
+// proposer_index.csv and assignments.csv are computed for the synthetic registries described in their headers
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	shuffle "github.com/protolambda/eth2-shuffle"
+)
+
+const (
+	slotsPerEpoch        = 32
+	targetCommitteeSize  = 128
+	maxCommitteesPerSlot = 64
+	maxEffectiveBalance  = 32000000000
+	rounds               = 90
+)
+
+var domainProposer = []byte{0, 0, 0, 0}
+var domainAttester = []byte{1, 0, 0, 0}
+
+func hashFn(in []byte) []byte {
+	h := sha256.Sum256(in)
+	return h[:]
+}
+
+func bytes8(x uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, x)
+	return b
+}
+
+func hash(in []byte) [32]byte { return sha256.Sum256(in) }
+
+// seed = hash(domain_type + uint_to_bytes(epoch) + mix)
+func seed(mix [32]byte, epoch uint64, domain []byte) [32]byte {
+	b := append(append(append([]byte{}, domain...), bytes8(epoch)...), mix[:]...)
+	return hash(b)
+}
+
+// SlotCommitteeCount of prysm
+func slotCommitteeCount(active uint64) uint64 {
+	c := active / slotsPerEpoch / targetCommitteeSize
+	if c > maxCommitteesPerSlot {
+		return maxCommitteesPerSlot
+	}
+	if c == 0 {
+		return 1
+	}
+	return c
+}
+
+// ComputeProposerIndex of prysm
+func computeProposerIndex(balances map[uint64]uint64, active []uint64, s [32]byte) uint64 {
+	length := uint64(len(active))
+	for i := uint64(0); ; i++ {
+		candidate := active[shuffle.PermuteIndex(hashFn, rounds, i%length, length, s)]
+		b := append(append([]byte{}, s[:]...), bytes8(i/32)...)
+		randomByte := hash(b)[i%32]
+		if balances[candidate]*255 >= maxEffectiveBalance*uint64(randomByte) {
+			return candidate
+		}
+	}
+}
+
+// registry of the fixture: every validator below n, but the ones with index%11 == 3, is active,
+// effective balance is 16 ETH for index%7 == 0, 31 ETH for index%5 == 0 and 32 ETH otherwise
+func registry(n uint64) ([]uint64, map[uint64]uint64) {
+	active := make([]uint64, 0, n)
+	balances := make(map[uint64]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		switch {
+		case i%7 == 0:
+			balances[i] = 16000000000
+		case i%5 == 0:
+			balances[i] = 31000000000
+		default:
+			balances[i] = 32000000000
+		}
+		if i%11 != 3 {
+			active = append(active, i)
+		}
+	}
+	return active, balances
+}
+
+func join(list []uint64) string {
+	s := make([]string, len(list))
+	for i, x := range list {
+		s[i] = strconv.FormatUint(x, 10)
+	}
+	return strings.Join(s, ":")
+}
+
+// create opens the vectors file in the testdata directory
+func create(name string) *os.File {
+	file, err := os.Create("../" + name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return file
+}
+
+func writeMapping() {
+	w := create("shuffle_mapping.csv")
+	defer w.Close()
+	fmt.Fprintln(w, "# shuffling/core vectors of consensus-spec-tests for the seeds hash(uint_to_bytes4(i)), i below 10,")
+	fmt.Fprintln(w, "# computed with github.com/protolambda/eth2-shuffle v1.1.0, see gen")
+	fmt.Fprintln(w, "# seed,count,mapping")
+	for i := uint32(0); i < 10; i++ {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, i)
+		s := hash(b)
+		for _, count := range []uint64{0, 1, 2, 3, 5, 10, 33, 100, 1000} {
+			mapping := make([]uint64, count)
+			for index := range mapping {
+				mapping[index] = shuffle.PermuteIndex(hashFn, rounds, uint64(index), count, s)
+			}
+			fmt.Fprintf(w, "%s,%d,%s\n", hex.EncodeToString(s[:]), count, join(mapping))
+		}
+	}
+}
+
+func writeProposers() {
+	w := create("proposer_index.csv")
+	defer w.Close()
+	fmt.Fprintln(w, "# proposers of the registry i of 10+7*i validators: validators with index%6 == i%6 are not active,")
+	fmt.Fprintln(w, "# effective balance of the validator j is 17+(7*j+i)%16 ETH, the seed is hash(\"proposer-i\").")
+	fmt.Fprintln(w, "# Computed with the proposer selection of github.com/prysmaticlabs/prysm v1.4.4, see gen")
+	fmt.Fprintln(w, "# seed,effective balances in ETH of validators 0..n-1,active indices,proposer")
+	for i := uint64(0); i < 20; i++ {
+		s := hash([]byte(fmt.Sprintf("proposer-%d", i)))
+		n := 10 + 7*i
+		ether := make([]uint64, n)
+		balances := make(map[uint64]uint64, n)
+		active := make([]uint64, 0, n)
+		for j := uint64(0); j < n; j++ {
+			ether[j] = 17 + (7*j+i)%16
+			balances[j] = ether[j] * 1000000000
+			if j%6 != i%6 {
+				active = append(active, j)
+			}
+		}
+		proposer := computeProposerIndex(balances, active, s)
+		fmt.Fprintf(w, "%s,%s,%s,%d\n", hex.EncodeToString(s[:]), join(ether), join(active), proposer)
+	}
+}
+
+func main() {
+	writeMapping()
+	writeProposers()
+
+	w := create("assignments.csv")
+	defer w.Close()
+	fmt.Fprintln(w, "# assignments of the registry of n validators: validators with index%11 == 3 are not active,")
+	fmt.Fprintln(w, "# effective balance is 16 ETH for index%7 == 0, 31 ETH for index%5 == 0 and 32 ETH otherwise.")
+	fmt.Fprintln(w, "# Computed with github.com/protolambda/eth2-shuffle v1.1.0 and the committee and proposer")
+	fmt.Fprintln(w, "# selection of github.com/prysmaticlabs/prysm v1.4.4 beacon-chain/core/helpers, see gen")
+	fmt.Fprintln(w, "# epoch,n,randao mix,slot,proposer,committees of the slot")
+	for _, c := range []struct{ epoch, n uint64 }{{5, 20}, {7, 300}, {9, 9100}} {
+		mix := hash([]byte(fmt.Sprintf("randao-mix-%d", c.epoch)))
+		active, balances := registry(c.n)
+		total := uint64(len(active))
+		perSlot := slotCommitteeCount(total)
+		count := perSlot * slotsPerEpoch
+
+		attester := seed(mix, c.epoch, domainAttester)
+		// ComputeCommittee of prysm: UnshuffleList of the active indices, split by SplitOffset
+		shuffled := append([]uint64{}, active...)
+		shuffle.UnshuffleList(hashFn, shuffled, rounds, attester)
+		proposer := seed(mix, c.epoch, domainProposer)
+		for i := uint64(0); i < slotsPerEpoch; i++ {
+			slot := c.epoch*slotsPerEpoch + i
+			fields := []string{
+				strconv.FormatUint(c.epoch, 10),
+				strconv.FormatUint(c.n, 10),
+				hex.EncodeToString(mix[:]),
+				strconv.FormatUint(slot, 10),
+				strconv.FormatUint(computeProposerIndex(balances, active, hash(append(append([]byte{}, proposer[:]...), bytes8(slot)...))), 10),
+			}
+			for index := uint64(0); index < perSlot; index++ {
+				k := i*perSlot + index
+				fields = append(fields, join(shuffled[total*k/count:total*(k+1)/count]))
+			}
+			fmt.Fprintln(w, strings.Join(fields, ","))
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"sync"
+
 	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
 )
 
@@ -20,6 +22,14 @@ type ChainHead struct {
 	PreviousJustifiedBlockRoot []byte
 }
 
+// ChainHeadSnapshot is the chain head observed when the epoch was cached
+type ChainHeadSnapshot struct {
+	Epoch uint64
+	// Timestamp is the unix time of the observation
+	Timestamp int64
+	ChainHead
+}
+
 // EpochData is a struct to hold epoch data
 type EpochData struct {
 	Epoch                   uint64
@@ -27,6 +37,8 @@ type EpochData struct {
 	ValidatorAssignments    *Assignments
 	Blocks                  map[uint64]map[string]*Block
 	EpochParticipationStats *ValidatorParticipation
+	// Proposals are the outcomes of the proposer duties, derived from the blocks
+	Proposals *EpochProposals
 }
 
 // ValidatorParticipation is a struct to hold validator participation data
@@ -56,11 +68,13 @@ type Validator struct {
 	WithdrawableEpoch          uint64 `db:"withdrawableepoch"`
 	WithdrawalCredentials      []byte `db:"withdrawalcredentials"`
 
-	BalanceActivation uint64 `db:"balanceactivation"`
-	Balance1d         uint64 `db:"balance1d"`
-	Balance7d         uint64 `db:"balance7d"`
-	Balance31d        uint64 `db:"balance31d"`
-	Status            string `db:"status"`
+	BalanceActivation uint64          `db:"balanceactivation"`
+	Balance1d         uint64          `db:"balance1d"`
+	Balance7d         uint64          `db:"balance7d"`
+	Balance31d        uint64          `db:"balance31d"`
+	Status            ValidatorStatus `db:"status"`
+	// Lookback are the balances by the names of the configured windows
+	Lookback map[string]uint64 `db:"-"`
 }
 
 // ValidatorF is a cachable fixed size struct to hold validator data
@@ -79,27 +93,8 @@ type ValidatorF struct {
 	Balance1d                  uint64   `db:"balance1d" json:"b1"`
 	Balance7d                  uint64   `db:"balance7d" json:"b7"`
 	Balance31d                 uint64   `db:"balance31d" json:"b32"`
-}
-
-func (src *ValidatorF) ToValidator() *Validator {
-	res := &Validator{
-		Index:                      src.Index,
-		Balance:                    src.Balance,
-		EffectiveBalance:           src.EffectiveBalance,
-		Slashed:                    src.Slashed,
-		ActivationEligibilityEpoch: src.ActivationEligibilityEpoch,
-		ActivationEpoch:            src.ActivationEpoch,
-		ExitEpoch:                  src.ExitEpoch,
-		WithdrawableEpoch:          src.WithdrawableEpoch,
-		BalanceActivation:          src.BalanceActivation,
-		Balance1d:                  src.Balance1d,
-		Balance7d:                  src.Balance7d,
-		Balance31d:                 src.Balance31d,
-		Status:                     "",
-	}
-	copy(res.PublicKey, src.PublicKey[:])
-	copy(res.WithdrawalCredentials, src.WithdrawalCredentials[:])
-	return res
+	// Status is derived at the epoch of the validator set
+	Status ValidatorStatus `db:"status" json:"s"`
 }
 
 // ValidatorQueue is a struct to hold validator queue data
@@ -111,6 +106,26 @@ type ValidatorQueue struct {
 	ExitValidatorIndices       []uint64
 }
 
+// Statuses of the block at the slot
+const (
+	// BlockScheduled is the placeholder of the slot, which is not reached yet
+	BlockScheduled uint64 = iota
+	// BlockProposed is the block of the canonical chain
+	BlockProposed
+	// BlockMissed is the placeholder of the past slot without a block
+	BlockMissed
+	// BlockOrphaned is the block, which is not canonical
+	BlockOrphaned
+)
+
+// BlockStatus returns the status of the block, which is known to the node
+func BlockStatus(canonical bool) uint64 {
+	if canonical {
+		return BlockProposed
+	}
+	return BlockOrphaned
+}
+
 // Block is a struct to hold block data
 type Block struct {
 	Status            uint64
@@ -229,6 +244,19 @@ type BlockComparisonContainer struct {
 	Node  *MinimalBlock
 }
 
+// ReorgEvent describes the slots which canonical blocks were replaced on the node
+type ReorgEvent struct {
+	// Epoch is the first reorged epoch, the reorg may continue in the next ones
+	Epoch uint64
+	// Slot is the first slot with the replaced canonical block
+	Slot uint64
+	// Depth is the number of slots from the common ancestor of the old and the new chain to the last replaced slot
+	Depth uint64
+	// OldRoots and NewRoots are canonical roots of the replaced slots, empty for no block
+	OldRoots [][]byte
+	NewRoots [][]byte
+}
+
 type AssignmentSlot struct {
 	Proposer   uint64
 	Committees [][]uint64
@@ -245,8 +273,21 @@ type Assignments struct {
 	NumAssignments uint64
 	// array of committees
 	Assignments []AssignmentSlot
+
+	// reverse index of the duties, not encoded with the assignments
+	duties    *DutyIndex
+	dutiesMux sync.Mutex
 }
 
+// ValidatorAt returns the validator at the position of the committee of the slot,
+// false if there is no such position
 func (a *Assignments) ValidatorAt(slot uint64, committee uint64, index uint64) (uint64, bool) {
-	return a.Assignments[slot-a.FirstSlot].Committees[committee][index], true
+	if slot < a.FirstSlot || slot-a.FirstSlot >= uint64(len(a.Assignments)) {
+		return 0, false
+	}
+	committees := a.Assignments[slot-a.FirstSlot].Committees
+	if committee >= uint64(len(committees)) || index >= uint64(len(committees[committee])) {
+		return 0, false
+	}
+	return committees[committee][index], true
 }
@@ -0,0 +1,13 @@
+package types
+
+// SlotsPerEpoch is the number of slots in the epoch
+const SlotsPerEpoch = 32
+
+// SecondsPerSlot is the duration of the slot
+const SecondsPerSlot = 12
+
+// EpochsPerDay is the number of 6.4 minutes epochs in 24 hours
+const EpochsPerDay = 24 * 3600 / (SecondsPerSlot * SlotsPerEpoch)
+
+// EpochsPerYear is the number of epochs in 365 days
+const EpochsPerYear = 365 * EpochsPerDay
@@ -0,0 +1,349 @@
+package types
+
+import (
+	eth2types "github.com/prysmaticlabs/eth2-types"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// cloneBytes returns the copy of the slice, never nil
+func cloneBytes(src []byte) []byte {
+	return append([]byte{}, src...)
+}
+
+// ToValidator converts the cached validator, the keys are copied
+func (src *ValidatorF) ToValidator() *Validator {
+	return &Validator{
+		Index:                      src.Index,
+		PublicKey:                  cloneBytes(src.PublicKey[:]),
+		Balance:                    src.Balance,
+		EffectiveBalance:           src.EffectiveBalance,
+		Slashed:                    src.Slashed,
+		ActivationEligibilityEpoch: src.ActivationEligibilityEpoch,
+		ActivationEpoch:            src.ActivationEpoch,
+		ExitEpoch:                  src.ExitEpoch,
+		WithdrawableEpoch:          src.WithdrawableEpoch,
+		WithdrawalCredentials:      cloneBytes(src.WithdrawalCredentials[:]),
+		BalanceActivation:          src.BalanceActivation,
+		Balance1d:                  src.Balance1d,
+		Balance7d:                  src.Balance7d,
+		Balance31d:                 src.Balance31d,
+		Status:                     src.Status,
+	}
+}
+
+// ToValidatorF converts the validator into the cachable one,
+// the keys longer than 48 and 32 bytes are truncated
+func (src *Validator) ToValidatorF() ValidatorF {
+	res := ValidatorF{
+		Index:                      src.Index,
+		Balance:                    src.Balance,
+		EffectiveBalance:           src.EffectiveBalance,
+		Slashed:                    src.Slashed,
+		ActivationEligibilityEpoch: src.ActivationEligibilityEpoch,
+		ActivationEpoch:            src.ActivationEpoch,
+		ExitEpoch:                  src.ExitEpoch,
+		WithdrawableEpoch:          src.WithdrawableEpoch,
+		BalanceActivation:          src.BalanceActivation,
+		Balance1d:                  src.Balance1d,
+		Balance7d:                  src.Balance7d,
+		Balance31d:                 src.Balance31d,
+		Status:                     src.Status,
+	}
+	copy(res.PublicKey[:], src.PublicKey)
+	copy(res.WithdrawalCredentials[:], src.WithdrawalCredentials)
+	return res
+}
+
+// NewValidatorFFromPB converts the validator of the node with its index and balance at the epoch
+func NewValidatorFFromPB(index uint64, balance uint64, src *ethpb.Validator) ValidatorF {
+	res := ValidatorF{
+		Index:                      index,
+		Balance:                    balance,
+		EffectiveBalance:           src.EffectiveBalance,
+		Slashed:                    src.Slashed,
+		ActivationEligibilityEpoch: uint64(src.ActivationEligibilityEpoch),
+		ActivationEpoch:            uint64(src.ActivationEpoch),
+		ExitEpoch:                  uint64(src.ExitEpoch),
+		WithdrawableEpoch:          uint64(src.WithdrawableEpoch),
+	}
+	copy(res.PublicKey[:], src.PublicKey)
+	copy(res.WithdrawalCredentials[:], src.WithdrawalCredentials)
+	return res
+}
+
+// NewValidatorFromPB converts the validator of the node with its index and balance at the epoch
+func NewValidatorFromPB(index uint64, balance uint64, src *ethpb.Validator) *Validator {
+	return &Validator{
+		Index:                      index,
+		PublicKey:                  cloneBytes(src.PublicKey),
+		Balance:                    balance,
+		EffectiveBalance:           src.EffectiveBalance,
+		Slashed:                    src.Slashed,
+		ActivationEligibilityEpoch: uint64(src.ActivationEligibilityEpoch),
+		ActivationEpoch:            uint64(src.ActivationEpoch),
+		ExitEpoch:                  uint64(src.ExitEpoch),
+		WithdrawableEpoch:          uint64(src.WithdrawableEpoch),
+		WithdrawalCredentials:      cloneBytes(src.WithdrawalCredentials),
+	}
+}
+
+// ToPB converts the validator into the node's one, without the index and balances
+func (src *Validator) ToPB() *ethpb.Validator {
+	return &ethpb.Validator{
+		PublicKey:                  cloneBytes(src.PublicKey),
+		WithdrawalCredentials:      cloneBytes(src.WithdrawalCredentials),
+		EffectiveBalance:           src.EffectiveBalance,
+		Slashed:                    src.Slashed,
+		ActivationEligibilityEpoch: eth2types.Epoch(src.ActivationEligibilityEpoch),
+		ActivationEpoch:            eth2types.Epoch(src.ActivationEpoch),
+		ExitEpoch:                  eth2types.Epoch(src.ExitEpoch),
+		WithdrawableEpoch:          eth2types.Epoch(src.WithdrawableEpoch),
+	}
+}
+
+// ToPB converts the cached validator into the node's one, without the index and balances
+func (src *ValidatorF) ToPB() *ethpb.Validator {
+	return src.ToValidator().ToPB()
+}
+
+// NewCheckpointFromPB converts the checkpoint of the node
+func NewCheckpointFromPB(src *ethpb.Checkpoint) *Checkpoint {
+	if src == nil {
+		return nil
+	}
+	return &Checkpoint{Epoch: uint64(src.Epoch), Root: src.Root}
+}
+
+// ToPB converts the checkpoint into the node's one
+func (src *Checkpoint) ToPB() *ethpb.Checkpoint {
+	if src == nil {
+		return nil
+	}
+	return &ethpb.Checkpoint{Epoch: eth2types.Epoch(src.Epoch), Root: src.Root}
+}
+
+// NewAttestationDataFromPB converts the attestation data of the node
+func NewAttestationDataFromPB(src *ethpb.AttestationData) *AttestationData {
+	if src == nil {
+		return nil
+	}
+	return &AttestationData{
+		Slot:            uint64(src.Slot),
+		CommitteeIndex:  uint64(src.CommitteeIndex),
+		BeaconBlockRoot: src.BeaconBlockRoot,
+		Source:          NewCheckpointFromPB(src.Source),
+		Target:          NewCheckpointFromPB(src.Target),
+	}
+}
+
+// ToPB converts the attestation data into the node's one
+func (src *AttestationData) ToPB() *ethpb.AttestationData {
+	if src == nil {
+		return nil
+	}
+	return &ethpb.AttestationData{
+		Slot:            eth2types.Slot(src.Slot),
+		CommitteeIndex:  eth2types.CommitteeIndex(src.CommitteeIndex),
+		BeaconBlockRoot: src.BeaconBlockRoot,
+		Source:          src.Source.ToPB(),
+		Target:          src.Target.ToPB(),
+	}
+}
+
+// NewAttestationFromPB converts the attestation of the node,
+// the attesters are resolved with the assignments of the epoch
+func NewAttestationFromPB(src *ethpb.Attestation) *Attestation {
+	return &Attestation{
+		AggregationBits: src.AggregationBits,
+		Data:            NewAttestationDataFromPB(src.Data),
+		Signature:       src.Signature,
+	}
+}
+
+// ToPB converts the attestation into the node's one
+func (src *Attestation) ToPB() *ethpb.Attestation {
+	return &ethpb.Attestation{
+		AggregationBits: src.AggregationBits,
+		Data:            src.Data.ToPB(),
+		Signature:       src.Signature,
+	}
+}
+
+// NewIndexedAttestationFromPB converts the indexed attestation of the node
+func NewIndexedAttestationFromPB(src *ethpb.IndexedAttestation) *IndexedAttestation {
+	return &IndexedAttestation{
+		Data:             NewAttestationDataFromPB(src.Data),
+		AttestingIndices: src.AttestingIndices,
+		Signature:        src.Signature,
+	}
+}
+
+// ToPB converts the indexed attestation into the node's one
+func (src *IndexedAttestation) ToPB() *ethpb.IndexedAttestation {
+	return &ethpb.IndexedAttestation{
+		Data:             src.Data.ToPB(),
+		AttestingIndices: src.AttestingIndices,
+		Signature:        src.Signature,
+	}
+}
+
+// newHeaderFromPB converts the signed block header of the node into the block without the body
+func newHeaderFromPB(src *ethpb.SignedBeaconBlockHeader) *Block {
+	return &Block{
+		Proposer:   uint64(src.Header.ProposerIndex),
+		Slot:       uint64(src.Header.Slot),
+		ParentRoot: src.Header.ParentRoot,
+		StateRoot:  src.Header.StateRoot,
+		Signature:  src.Signature,
+		BodyRoot:   src.Header.BodyRoot,
+	}
+}
+
+func (src *Block) toHeaderPB() *ethpb.SignedBeaconBlockHeader {
+	return &ethpb.SignedBeaconBlockHeader{
+		Header: &ethpb.BeaconBlockHeader{
+			Slot:          eth2types.Slot(src.Slot),
+			ProposerIndex: eth2types.ValidatorIndex(src.Proposer),
+			ParentRoot:    src.ParentRoot,
+			StateRoot:     src.StateRoot,
+			BodyRoot:      src.BodyRoot,
+		},
+		Signature: src.Signature,
+	}
+}
+
+// NewBlockFromPB converts the block container of the node into the proposed block,
+// the attesters of its attestations are not resolved
+func NewBlockFromPB(src *ethpb.BeaconBlockContainer) *Block {
+	block := src.Block.Block
+	body := block.Body
+	b := &Block{
+		Status:            BlockStatus(src.Canonical),
+		Canonical:         src.Canonical,
+		BlockRoot:         src.BlockRoot,
+		Slot:              uint64(block.Slot),
+		ParentRoot:        block.ParentRoot,
+		StateRoot:         block.StateRoot,
+		Signature:         src.Block.Signature,
+		RandaoReveal:      body.RandaoReveal,
+		Graffiti:          body.Graffiti,
+		Eth1Data:          &Eth1Data{},
+		ProposerSlashings: make([]*ProposerSlashing, len(body.ProposerSlashings)),
+		AttesterSlashings: make([]*AttesterSlashing, len(body.AttesterSlashings)),
+		Attestations:      make([]*Attestation, len(body.Attestations)),
+		Deposits:          make([]*Deposit, len(body.Deposits)),
+		VoluntaryExits:    make([]*VoluntaryExit, len(body.VoluntaryExits)),
+		Proposer:          uint64(block.ProposerIndex),
+	}
+	// blocks of the genesis epoch have no Eth1Data
+	if body.Eth1Data != nil {
+		b.Eth1Data = &Eth1Data{
+			DepositRoot:  body.Eth1Data.DepositRoot,
+			DepositCount: body.Eth1Data.DepositCount,
+			BlockHash:    body.Eth1Data.BlockHash,
+		}
+	}
+	for i, proposerSlashing := range body.ProposerSlashings {
+		b.ProposerSlashings[i] = &ProposerSlashing{
+			ProposerIndex: uint64(proposerSlashing.Header_1.Header.ProposerIndex),
+			Header1:       newHeaderFromPB(proposerSlashing.Header_1),
+			Header2:       newHeaderFromPB(proposerSlashing.Header_2),
+		}
+	}
+	for i, attesterSlashing := range body.AttesterSlashings {
+		b.AttesterSlashings[i] = &AttesterSlashing{
+			Attestation1: NewIndexedAttestationFromPB(attesterSlashing.Attestation_1),
+			Attestation2: NewIndexedAttestationFromPB(attesterSlashing.Attestation_2),
+		}
+	}
+	for i, attestation := range body.Attestations {
+		b.Attestations[i] = NewAttestationFromPB(attestation)
+	}
+	for i, deposit := range body.Deposits {
+		b.Deposits[i] = &Deposit{
+			Proof:                 deposit.Proof,
+			PublicKey:             deposit.Data.PublicKey,
+			WithdrawalCredentials: deposit.Data.WithdrawalCredentials,
+			Amount:                deposit.Data.Amount,
+			Signature:             deposit.Data.Signature,
+		}
+	}
+	for i, voluntaryExit := range body.VoluntaryExits {
+		b.VoluntaryExits[i] = &VoluntaryExit{
+			Epoch:          uint64(voluntaryExit.Exit.Epoch),
+			ValidatorIndex: uint64(voluntaryExit.Exit.ValidatorIndex),
+			Signature:      voluntaryExit.Signature,
+		}
+	}
+	return b
+}
+
+// ToPB converts the proposed block into the block container of the node,
+// the status, body root and attesters have no place there
+func (src *Block) ToPB() *ethpb.BeaconBlockContainer {
+	body := &ethpb.BeaconBlockBody{
+		RandaoReveal:      src.RandaoReveal,
+		Graffiti:          src.Graffiti,
+		ProposerSlashings: make([]*ethpb.ProposerSlashing, len(src.ProposerSlashings)),
+		AttesterSlashings: make([]*ethpb.AttesterSlashing, len(src.AttesterSlashings)),
+		Attestations:      make([]*ethpb.Attestation, len(src.Attestations)),
+		Deposits:          make([]*ethpb.Deposit, len(src.Deposits)),
+		VoluntaryExits:    make([]*ethpb.SignedVoluntaryExit, len(src.VoluntaryExits)),
+	}
+	if src.Eth1Data != nil {
+		body.Eth1Data = &ethpb.Eth1Data{
+			DepositRoot:  src.Eth1Data.DepositRoot,
+			DepositCount: src.Eth1Data.DepositCount,
+			BlockHash:    src.Eth1Data.BlockHash,
+		}
+	}
+	for i, proposerSlashing := range src.ProposerSlashings {
+		body.ProposerSlashings[i] = &ethpb.ProposerSlashing{
+			Header_1: proposerSlashing.Header1.toHeaderPB(),
+			Header_2: proposerSlashing.Header2.toHeaderPB(),
+		}
+	}
+	for i, attesterSlashing := range src.AttesterSlashings {
+		body.AttesterSlashings[i] = &ethpb.AttesterSlashing{
+			Attestation_1: attesterSlashing.Attestation1.ToPB(),
+			Attestation_2: attesterSlashing.Attestation2.ToPB(),
+		}
+	}
+	for i, attestation := range src.Attestations {
+		body.Attestations[i] = attestation.ToPB()
+	}
+	for i, deposit := range src.Deposits {
+		body.Deposits[i] = &ethpb.Deposit{
+			Proof: deposit.Proof,
+			Data: &ethpb.Deposit_Data{
+				PublicKey:             deposit.PublicKey,
+				WithdrawalCredentials: deposit.WithdrawalCredentials,
+				Amount:                deposit.Amount,
+				Signature:             deposit.Signature,
+			},
+		}
+	}
+	for i, voluntaryExit := range src.VoluntaryExits {
+		body.VoluntaryExits[i] = &ethpb.SignedVoluntaryExit{
+			Exit: &ethpb.VoluntaryExit{
+				Epoch:          eth2types.Epoch(voluntaryExit.Epoch),
+				ValidatorIndex: eth2types.ValidatorIndex(voluntaryExit.ValidatorIndex),
+			},
+			Signature: voluntaryExit.Signature,
+		}
+	}
+	return &ethpb.BeaconBlockContainer{
+		Block: &ethpb.SignedBeaconBlock{
+			Block: &ethpb.BeaconBlock{
+				Slot:          eth2types.Slot(src.Slot),
+				ProposerIndex: eth2types.ValidatorIndex(src.Proposer),
+				ParentRoot:    src.ParentRoot,
+				StateRoot:     src.StateRoot,
+				Body:          body,
+			},
+			Signature: src.Signature,
+		},
+		BlockRoot: src.BlockRoot,
+		Canonical: src.Canonical,
+	}
+}
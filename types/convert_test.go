@@ -0,0 +1,163 @@
+package types
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/golang/protobuf/proto"
+	eth2types "github.com/prysmaticlabs/eth2-types"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+func TestValidatorRoundTrip(t *testing.T) {
+	cached := func(v ValidatorF) bool {
+		return reflect.DeepEqual(v.ToValidator().ToValidatorF(), v)
+	}
+	if err := quick.Check(cached, nil); err != nil {
+		t.Error(err)
+	}
+
+	// the node has neither the index and balances, nor the status
+	node := func(v ValidatorF) bool {
+		out := NewValidatorFFromPB(v.Index, v.Balance, v.ToPB())
+		v.BalanceActivation, v.Balance1d, v.Balance7d, v.Balance31d, v.Status = 0, 0, 0, 0, StatusUnknown
+		return reflect.DeepEqual(out, v) &&
+			reflect.DeepEqual(NewValidatorFromPB(v.Index, v.Balance, v.ToPB()), v.ToValidator())
+	}
+	if err := quick.Check(node, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestToValidatorCopiesKeys(t *testing.T) {
+	v := ValidatorF{}
+	v.PublicKey[0], v.WithdrawalCredentials[31] = 0xaa, 0xbb
+	out := v.ToValidator()
+	if len(out.PublicKey) != 48 || out.PublicKey[0] != 0xaa || len(out.WithdrawalCredentials) != 32 || out.WithdrawalCredentials[31] != 0xbb {
+		t.Fatalf("keys are lost %x %x", out.PublicKey, out.WithdrawalCredentials)
+	}
+	out.PublicKey[0] = 0
+	if v.PublicKey[0] != 0xaa {
+		t.Error("keys are shared with the cached validator")
+	}
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	roundTrip := func(seed int64) bool {
+		src := randomBlockPB(rand.New(rand.NewSource(seed)))
+		b := NewBlockFromPB(src)
+		return proto.Equal(b.ToPB(), src) && reflect.DeepEqual(NewBlockFromPB(b.ToPB()), b)
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	res := make([]byte, n)
+	r.Read(res)
+	return res
+}
+
+func randomCheckpoint(r *rand.Rand) *ethpb.Checkpoint {
+	return &ethpb.Checkpoint{Epoch: eth2types.Epoch(r.Uint64()), Root: randomBytes(r, 32)}
+}
+
+func randomAttestationData(r *rand.Rand) *ethpb.AttestationData {
+	return &ethpb.AttestationData{
+		Slot:            eth2types.Slot(r.Uint64()),
+		CommitteeIndex:  eth2types.CommitteeIndex(r.Uint64()),
+		BeaconBlockRoot: randomBytes(r, 32),
+		Source:          randomCheckpoint(r),
+		Target:          randomCheckpoint(r),
+	}
+}
+
+func randomIndexedAttestation(r *rand.Rand) *ethpb.IndexedAttestation {
+	return &ethpb.IndexedAttestation{
+		AttestingIndices: []uint64{r.Uint64(), r.Uint64()},
+		Data:             randomAttestationData(r),
+		Signature:        randomBytes(r, 96),
+	}
+}
+
+func randomHeader(r *rand.Rand) *ethpb.SignedBeaconBlockHeader {
+	return &ethpb.SignedBeaconBlockHeader{
+		Header: &ethpb.BeaconBlockHeader{
+			Slot:          eth2types.Slot(r.Uint64()),
+			ProposerIndex: eth2types.ValidatorIndex(r.Uint64()),
+			ParentRoot:    randomBytes(r, 32),
+			StateRoot:     randomBytes(r, 32),
+			BodyRoot:      randomBytes(r, 32),
+		},
+		Signature: randomBytes(r, 96),
+	}
+}
+
+// randomBlockPB returns the block container with every field filled and random number of operations
+func randomBlockPB(r *rand.Rand) *ethpb.BeaconBlockContainer {
+	body := &ethpb.BeaconBlockBody{
+		RandaoReveal: randomBytes(r, 96),
+		Eth1Data: &ethpb.Eth1Data{
+			DepositRoot:  randomBytes(r, 32),
+			DepositCount: r.Uint64(),
+			BlockHash:    randomBytes(r, 32),
+		},
+		Graffiti: randomBytes(r, 32),
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		body.ProposerSlashings = append(body.ProposerSlashings, &ethpb.ProposerSlashing{
+			Header_1: randomHeader(r),
+			Header_2: randomHeader(r),
+		})
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		body.AttesterSlashings = append(body.AttesterSlashings, &ethpb.AttesterSlashing{
+			Attestation_1: randomIndexedAttestation(r),
+			Attestation_2: randomIndexedAttestation(r),
+		})
+	}
+	for i := r.Intn(8); i > 0; i-- {
+		body.Attestations = append(body.Attestations, &ethpb.Attestation{
+			AggregationBits: randomBytes(r, 1+r.Intn(16)),
+			Data:            randomAttestationData(r),
+			Signature:       randomBytes(r, 96),
+		})
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		body.Deposits = append(body.Deposits, &ethpb.Deposit{
+			Proof: [][]byte{randomBytes(r, 32), randomBytes(r, 32)},
+			Data: &ethpb.Deposit_Data{
+				PublicKey:             randomBytes(r, 48),
+				WithdrawalCredentials: randomBytes(r, 32),
+				Amount:                r.Uint64(),
+				Signature:             randomBytes(r, 96),
+			},
+		})
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		body.VoluntaryExits = append(body.VoluntaryExits, &ethpb.SignedVoluntaryExit{
+			Exit: &ethpb.VoluntaryExit{
+				Epoch:          eth2types.Epoch(r.Uint64()),
+				ValidatorIndex: eth2types.ValidatorIndex(r.Uint64()),
+			},
+			Signature: randomBytes(r, 96),
+		})
+	}
+	return &ethpb.BeaconBlockContainer{
+		Block: &ethpb.SignedBeaconBlock{
+			Block: &ethpb.BeaconBlock{
+				Slot:          eth2types.Slot(r.Uint64()),
+				ProposerIndex: eth2types.ValidatorIndex(r.Uint64()),
+				ParentRoot:    randomBytes(r, 32),
+				StateRoot:     randomBytes(r, 32),
+				Body:          body,
+			},
+			Signature: randomBytes(r, 96),
+		},
+		BlockRoot: randomBytes(r, 32),
+		Canonical: r.Intn(2) == 0,
+	}
+}
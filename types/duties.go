@@ -0,0 +1,69 @@
+package types
+
+// AttesterDuty is the position of the validator in the committee, which attests the slot
+type AttesterDuty struct {
+	Slot           uint64
+	CommitteeIndex uint64
+	Position       uint64
+}
+
+// DutyIndex maps the validators to their duties of the epoch
+type DutyIndex struct {
+	Epoch uint64
+	// Attesters is the only attester duty of the validator in the epoch
+	Attesters map[uint64]AttesterDuty
+	// Proposers are the slots proposed by the validator, in the ascending order
+	Proposers map[uint64][]uint64
+}
+
+// NewDutyIndex builds the reverse index of the assignments
+func NewDutyIndex(a *Assignments) *DutyIndex {
+	d := &DutyIndex{
+		Epoch:     uint64(a.Epoch),
+		Attesters: make(map[uint64]AttesterDuty, a.NumAssignments),
+		Proposers: make(map[uint64][]uint64, len(a.Assignments)),
+	}
+	for slotIndex, assignment := range a.Assignments {
+		slot := a.FirstSlot + uint64(slotIndex)
+		d.Proposers[assignment.Proposer] = append(d.Proposers[assignment.Proposer], slot)
+		for committeeIndex, committee := range assignment.Committees {
+			for position, validator := range committee {
+				d.Attesters[validator] = AttesterDuty{
+					Slot:           slot,
+					CommitteeIndex: uint64(committeeIndex),
+					Position:       uint64(position),
+				}
+			}
+		}
+	}
+	return d
+}
+
+// Duties returns the reverse index of the assignments,
+// it is built once on the first call unless the assignments were indexed
+func (a *Assignments) Duties() *DutyIndex {
+	a.dutiesMux.Lock()
+	defer a.dutiesMux.Unlock()
+	if a.duties == nil {
+		a.duties = NewDutyIndex(a)
+	}
+	return a.duties
+}
+
+// SetDuties indexes the assignments, before they are shared
+func (a *Assignments) SetDuties(d *DutyIndex) {
+	a.dutiesMux.Lock()
+	defer a.dutiesMux.Unlock()
+	a.duties = d
+}
+
+// AttesterDuty returns the attestation duty of the validator, false if it has none in the epoch
+func (a *Assignments) AttesterDuty(validator uint64) (AttesterDuty, bool) {
+	duty, found := a.Duties().Attesters[validator]
+	return duty, found
+}
+
+// ProposerSlots returns the slots of the epoch proposed by the validator
+func (a *Assignments) ProposerSlots(validator uint64) []uint64 {
+	return a.Duties().Proposers[validator]
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testAssignments() *Assignments {
+	return &Assignments{
+		Epoch:          2,
+		NumSlots:       2,
+		FirstSlot:      64,
+		NumAssignments: 6,
+		Assignments: []AssignmentSlot{
+			{Proposer: 4, Committees: [][]uint64{{1, 2}, {3}}},
+			{Proposer: 4, Committees: [][]uint64{{5, 4, 6}}},
+		},
+	}
+}
+
+func TestValidatorAtBounds(t *testing.T) {
+	a := testAssignments()
+	if v, found := a.ValidatorAt(65, 0, 2); !found || v != 6 {
+		t.Errorf("validator %v, %v", v, found)
+	}
+	for _, at := range [][3]uint64{{63, 0, 0}, {66, 0, 0}, {64, 2, 0}, {64, 1, 1}} {
+		if v, found := a.ValidatorAt(at[0], at[1], at[2]); found {
+			t.Errorf("validator %v is found out of range %v", v, at)
+		}
+	}
+}
+
+func TestDutyIndex(t *testing.T) {
+	a := testAssignments()
+	if duty, found := a.AttesterDuty(4); !found || duty != (AttesterDuty{Slot: 65, CommitteeIndex: 0, Position: 1}) {
+		t.Errorf("attester duty %+v, %v", duty, found)
+	}
+	if duty, found := a.AttesterDuty(3); !found || duty != (AttesterDuty{Slot: 64, CommitteeIndex: 1, Position: 0}) {
+		t.Errorf("attester duty %+v, %v", duty, found)
+	}
+	if _, found := a.AttesterDuty(7); found {
+		t.Error("duty of the validator out of the committees")
+	}
+	if slots := a.ProposerSlots(4); !reflect.DeepEqual(slots, []uint64{64, 65}) {
+		t.Errorf("proposer slots %v", slots)
+	}
+	if slots := a.ProposerSlots(1); len(slots) != 0 {
+		t.Errorf("unexpected proposer slots %v", slots)
+	}
+
+	if a.Duties() != a.Duties() {
+		t.Error("index is built on every call")
+	}
+	// every duty points back to the validator
+	for validator, duty := range a.Duties().Attesters {
+		if v, _ := a.ValidatorAt(duty.Slot, duty.CommitteeIndex, duty.Position); v != validator {
+			t.Errorf("duty %+v of %d points to %d", duty, validator, v)
+		}
+	}
+}
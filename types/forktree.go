@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ForkNode is the block of the fork tree
+type ForkNode struct {
+	Slot       uint64
+	Root       []byte
+	ParentRoot []byte
+	Proposer   uint64
+	Canonical  bool
+	// Attestations is the number of the attestations, included by the block
+	Attestations int
+	// Children are the blocks built on this one, sorted by the slot and the root
+	Children []*ForkNode
+}
+
+// ForkTree is the tree of the blocks of the range of slots, linked by the parent roots
+type ForkTree struct {
+	// FromSlot and ToSlot are the range of the slots, inclusive
+	FromSlot uint64
+	ToSlot   uint64
+	// Roots are the blocks, which parents are not in the range, sorted by the slot and the root
+	Roots []*ForkNode
+}
+
+// NewForkTree links the blocks of the range of slots by the parent roots,
+// the blocks out of the range and the duplicates of a root are skipped
+func NewForkTree(fromSlot, toSlot uint64, blocks []*Block) *ForkTree {
+	nodes := make(map[string]*ForkNode, len(blocks))
+	sorted := make([]*ForkNode, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Status == BlockScheduled || b.Status == BlockMissed || b.Slot < fromSlot || b.Slot > toSlot {
+			continue
+		}
+		if _, ok := nodes[string(b.BlockRoot)]; ok {
+			continue
+		}
+		node := &ForkNode{
+			Slot:         b.Slot,
+			Root:         b.BlockRoot,
+			ParentRoot:   b.ParentRoot,
+			Proposer:     b.Proposer,
+			Canonical:    b.Canonical,
+			Attestations: len(b.Attestations),
+		}
+		nodes[string(b.BlockRoot)] = node
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Slot != sorted[j].Slot {
+			return sorted[i].Slot < sorted[j].Slot
+		}
+		return string(sorted[i].Root) < string(sorted[j].Root)
+	})
+
+	tree := &ForkTree{FromSlot: fromSlot, ToSlot: toSlot, Roots: make([]*ForkNode, 0)}
+	for _, node := range sorted {
+		if parent, ok := nodes[string(node.ParentRoot)]; ok && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Roots = append(tree.Roots, node)
+		}
+	}
+	return tree
+}
+
+// Walk calls visit for every block of the tree, parents before children, with the depth from the root
+func (t *ForkTree) Walk(visit func(node *ForkNode, depth int)) {
+	var walk func(node *ForkNode, depth int)
+	walk = func(node *ForkNode, depth int) {
+		visit(node, depth)
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range t.Roots {
+		walk(root, 0)
+	}
+}
+
+// Get returns the block of the root
+func (t *ForkTree) Get(root []byte) (*ForkNode, bool) {
+	var res *ForkNode
+	t.Walk(func(node *ForkNode, _ int) {
+		if res == nil && string(node.Root) == string(root) {
+			res = node
+		}
+	})
+	return res, res != nil
+}
+
+// Heads returns the blocks without children, the tips of the forks
+func (t *ForkTree) Heads() []*ForkNode {
+	res := make([]*ForkNode, 0)
+	t.Walk(func(node *ForkNode, _ int) {
+		if len(node.Children) == 0 {
+			res = append(res, node)
+		}
+	})
+	return res
+}
+
+// String draws the tree, a block per line indented by its depth
+func (t *ForkTree) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "slots %d-%d\n", t.FromSlot, t.ToSlot)
+	t.Walk(func(node *ForkNode, depth int) {
+		status := "canonical"
+		if !node.Canonical {
+			status = "orphaned"
+		}
+		fmt.Fprintf(&sb, "%s%d 0x%x proposer %d %s\n", strings.Repeat("  ", depth+1), node.Slot, node.Root, node.Proposer, status)
+	})
+	return sb.String()
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewForkTree(t *testing.T) {
+	block := func(slot uint64, root, parent byte, canonical bool) *Block {
+		return &Block{Status: BlockStatus(canonical), Slot: slot, BlockRoot: []byte{root}, ParentRoot: []byte{parent}, Canonical: canonical}
+	}
+	blocks := []*Block{
+		block(34, 0x04, 0x03, true),
+		block(33, 0x03, 0x01, true),
+		// the fork at the slot 32, replaced by the slots 33-34
+		block(33, 0x13, 0x02, false),
+		block(32, 0x02, 0x01, false),
+		block(34, 0x04, 0x03, true),
+		{Status: BlockMissed, Slot: 35, BlockRoot: []byte{0x1}},
+	}
+
+	tree := NewForkTree(32, 63, append(blocks, block(64, 0x05, 0x04, true)))
+	if len(tree.Roots) != 2 || tree.Roots[0].Slot != 32 || tree.Roots[1].Slot != 33 {
+		t.Fatalf("unexpected roots %+v", tree.Roots)
+	}
+	heads := tree.Heads()
+	if len(heads) != 2 || heads[0].Root[0] != 0x13 || heads[1].Root[0] != 0x04 {
+		t.Errorf("unexpected heads %+v", heads)
+	}
+	if node, ok := tree.Get([]byte{0x03}); !ok || len(node.Children) != 1 || !node.Canonical {
+		t.Errorf("unexpected block %+v", node)
+	}
+	if _, ok := tree.Get([]byte{0x1}); ok {
+		t.Error("missed slot is in the tree")
+	}
+	expected := "slots 32-63\n" +
+		"  32 0x02 proposer 0 orphaned\n" +
+		"    33 0x13 proposer 0 orphaned\n" +
+		"  33 0x03 proposer 0 canonical\n" +
+		"    34 0x04 proposer 0 canonical\n"
+	if view := tree.String(); view != expected {
+		t.Errorf("unexpected view\n%s", strings.TrimSpace(view))
+	}
+}
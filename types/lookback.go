@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LookbackActivation is the name of the window since the activation of the validator
+const LookbackActivation = "activation"
+
+// Lookback is the window of the balance history of the validator,
+// the balance is taken that many epochs back or at the activation epoch
+type Lookback struct {
+	Name       string
+	Epochs     uint64
+	Activation bool
+}
+
+// ParseLookback reads the window like 1h, 1d, 30d, 365d or activation,
+// the duration is rounded down to the whole epochs of the given length
+func ParseLookback(name string, secondsPerEpoch uint64) (Lookback, error) {
+	if name == LookbackActivation {
+		return Lookback{Name: name, Activation: true}, nil
+	}
+	var duration time.Duration
+	if days := strings.TrimSuffix(name, "d"); days != name {
+		n, err := strconv.ParseUint(days, 10, 32)
+		if err != nil {
+			return Lookback{}, fmt.Errorf("lookback %q: %w", name, err)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(name); err != nil {
+			return Lookback{}, fmt.Errorf("lookback %q: %w", name, err)
+		}
+	}
+	epochs := uint64(duration/time.Second) / secondsPerEpoch
+	if epochs == 0 {
+		return Lookback{}, fmt.Errorf("lookback %q is shorter than the epoch", name)
+	}
+	return Lookback{Name: name, Epochs: epochs}, nil
+}
+
+// ParseLookbacks reads the comma-separated windows
+func ParseLookbacks(names string, secondsPerEpoch uint64) ([]Lookback, error) {
+	res := make([]Lookback, 0)
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		l, err := ParseLookback(name, secondsPerEpoch)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+// BalanceLookback is the balance history of the validators at the epoch
+type BalanceLookback struct {
+	Epoch   uint64
+	Windows []Lookback
+	// Balances of the validator are in the order of the windows,
+	// 0 for the activation window of the validator, which is not active yet
+	Balances map[uint64][]uint64
+}
+
+// Get returns the balance of the validator in the named window
+func (b *BalanceLookback) Get(index uint64, name string) (uint64, bool) {
+	balances, ok := b.Balances[index]
+	if !ok {
+		return 0, false
+	}
+	for i, w := range b.Windows {
+		if w.Name == name {
+			return balances[i], true
+		}
+	}
+	return 0, false
+}
+
+// Of returns balances of the validator by the window name
+func (b *BalanceLookback) Of(index uint64) map[string]uint64 {
+	balances, ok := b.Balances[index]
+	if !ok {
+		return nil
+	}
+	res := make(map[string]uint64, len(b.Windows))
+	for i, w := range b.Windows {
+		res[w.Name] = balances[i]
+	}
+	return res
+}
+
+// Apply fills the balances 1, 7 and 31 days ago and at the activation of the validator
+// with the windows of the same length, whatever their names are, like 24h or 1d
+func (b *BalanceLookback) Apply(v *ValidatorF) {
+	v.BalanceActivation, v.Balance1d, v.Balance7d, v.Balance31d = 0, 0, 0, 0
+	balances, ok := b.Balances[v.Index]
+	if !ok {
+		return
+	}
+	fixed := map[uint64]*uint64{
+		EpochsPerDay:      &v.Balance1d,
+		7 * EpochsPerDay:  &v.Balance7d,
+		31 * EpochsPerDay: &v.Balance31d,
+	}
+	for i, w := range b.Windows {
+		if w.Activation {
+			v.BalanceActivation = balances[i]
+		} else if balance, ok := fixed[w.Epochs]; ok {
+			*balance = balances[i]
+			delete(fixed, w.Epochs)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLookbacks(t *testing.T) {
+	windows, err := ParseLookbacks("1h, 1d,30d,365d,activation", 384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Lookback{
+		{Name: "1h", Epochs: 9},
+		{Name: "1d", Epochs: 225},
+		{Name: "30d", Epochs: 6750},
+		{Name: "365d", Epochs: 82125},
+		{Name: LookbackActivation, Activation: true},
+	}
+	if !reflect.DeepEqual(windows, expected) {
+		t.Errorf("unexpected windows %+v", windows)
+	}
+	for _, name := range []string{"1m", "xd", "week"} {
+		if _, err := ParseLookback(name, 384); err == nil {
+			t.Errorf("window %q is parsed", name)
+		}
+	}
+}
+
+func TestBalanceLookback(t *testing.T) {
+	windows, _ := ParseLookbacks("1h,1d,activation", 384)
+	lookback := &BalanceLookback{
+		Epoch:    300,
+		Windows:  windows,
+		Balances: map[uint64][]uint64{5: {3, 2, 1}},
+	}
+	if balance, ok := lookback.Get(5, "1d"); !ok || balance != 2 {
+		t.Errorf("1d balance %d", balance)
+	}
+	if _, ok := lookback.Get(5, "7d"); ok {
+		t.Error("balance of the missing window")
+	}
+	if _, ok := lookback.Get(6, "1d"); ok {
+		t.Error("balance of the missing validator")
+	}
+	if of := lookback.Of(5); !reflect.DeepEqual(of, map[string]uint64{"1h": 3, "1d": 2, "activation": 1}) {
+		t.Errorf("balances by window %v", of)
+	}
+	v := ValidatorF{Index: 5, Balance7d: 9}
+	lookback.Apply(&v)
+	if v.Balance1d != 2 || v.BalanceActivation != 1 || v.Balance7d != 0 {
+		t.Errorf("applied balances %+v", v)
+	}
+
+	// the fixed balances follow the length of the windows, not their names
+	windows, _ = ParseLookbacks("24h,168h,744h", 384)
+	lookback = &BalanceLookback{Epoch: 8000, Windows: windows, Balances: map[uint64][]uint64{5: {4, 5, 6}}}
+	lookback.Apply(&v)
+	if v.Balance1d != 4 || v.Balance7d != 5 || v.Balance31d != 6 || v.BalanceActivation != 0 {
+		t.Errorf("applied balances of the windows by length %+v", v)
+	}
+}
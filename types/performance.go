@@ -0,0 +1,80 @@
+package types
+
+import "sort"
+
+// AttestationPerformance tells how the validator fulfilled its attestation duty of the epoch
+type AttestationPerformance struct {
+	Index uint64
+	// Slot is the assigned slot of the attestation
+	Slot uint64
+	// InclusionSlot is the slot of the earliest canonical block with the attestation, 0 when it was not included
+	InclusionSlot uint64
+	// CorrectSource, CorrectTarget and CorrectHead tell whether any included attestation
+	// voted for the canonical checkpoints and the canonical head
+	CorrectSource bool
+	CorrectTarget bool
+	CorrectHead   bool
+}
+
+// Included tells whether the attestation was included into the canonical chain
+func (p *AttestationPerformance) Included() bool {
+	return p.InclusionSlot > 0
+}
+
+// InclusionDistance is the number of slots since the assigned slot till the inclusion, 0 when it was not included
+func (p *AttestationPerformance) InclusionDistance() uint64 {
+	if !p.Included() {
+		return 0
+	}
+	return p.InclusionSlot - p.Slot
+}
+
+// EpochPerformance is the attestation performance of the validators assigned in the epoch
+type EpochPerformance struct {
+	Epoch uint64
+	// Validators are sorted by the validator index
+	Validators []AttestationPerformance
+}
+
+// Get returns the performance of the validator, false if it had no attestation duty in the epoch
+func (e *EpochPerformance) Get(index uint64) (*AttestationPerformance, bool) {
+	i := sort.Search(len(e.Validators), func(i int) bool { return e.Validators[i].Index >= index })
+	if i == len(e.Validators) || e.Validators[i].Index != index {
+		return nil, false
+	}
+	return &e.Validators[i], true
+}
+
+// PerformanceSummary counts the attestations of the epoch
+type PerformanceSummary struct {
+	Assigned      int
+	Included      int
+	CorrectSource int
+	CorrectTarget int
+	CorrectHead   int
+	// TotalDistance is the sum of inclusion distances of the included attestations
+	TotalDistance uint64
+}
+
+// Summary counts the attestations of the epoch
+func (e *EpochPerformance) Summary() PerformanceSummary {
+	s := PerformanceSummary{Assigned: len(e.Validators)}
+	for i := range e.Validators {
+		p := &e.Validators[i]
+		if !p.Included() {
+			continue
+		}
+		s.Included++
+		s.TotalDistance += p.InclusionDistance()
+		if p.CorrectSource {
+			s.CorrectSource++
+		}
+		if p.CorrectTarget {
+			s.CorrectTarget++
+		}
+		if p.CorrectHead {
+			s.CorrectHead++
+		}
+	}
+	return s
+}
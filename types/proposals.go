@@ -0,0 +1,149 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ProposalStatus is the outcome of the proposer duty at the slot,
+// the codes of the scheduled, proposed and missed slots match the status of the block
+type ProposalStatus uint8
+
+const (
+	// ProposalScheduled is the slot, which is not reached yet
+	ProposalScheduled ProposalStatus = iota
+	// ProposalProposed is the slot with the canonical block
+	ProposalProposed
+	// ProposalMissed is the past slot without a block
+	ProposalMissed
+	// ProposalOrphaned is the slot, which blocks are not canonical
+	ProposalOrphaned
+)
+
+var proposalStatusNames = []string{
+	"scheduled",
+	"proposed",
+	"missed",
+	"orphaned",
+}
+
+func (s ProposalStatus) String() string {
+	if int(s) >= len(proposalStatusNames) {
+		return fmt.Sprintf("proposal(%d)", uint8(s))
+	}
+	return proposalStatusNames[s]
+}
+
+// ParseProposalStatus returns the status by its name
+func ParseProposalStatus(name string) (ProposalStatus, error) {
+	for i, n := range proposalStatusNames {
+		if n == name {
+			return ProposalStatus(i), nil
+		}
+	}
+	return ProposalScheduled, fmt.Errorf("unknown proposal status %q", name)
+}
+
+// MarshalText writes the status as its name
+func (s ProposalStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads the status from its name
+func (s *ProposalStatus) UnmarshalText(text []byte) error {
+	status, err := ParseProposalStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// SlotProposal is the outcome of the proposer duty at the slot
+type SlotProposal struct {
+	Slot     uint64         `json:"slot"`
+	Proposer uint64         `json:"proposer"`
+	Status   ProposalStatus `json:"status"`
+	// BlockRoot is the canonical block or the first orphaned one, empty without a block
+	BlockRoot []byte `json:"block_root,omitempty"`
+}
+
+// EpochProposals are the outcomes of the proposer duties at the slots of the epoch
+type EpochProposals struct {
+	Epoch uint64
+	// Slots are sorted by the slot
+	Slots []SlotProposal
+}
+
+// NewEpochProposals derives the outcomes from the assigned proposers and the blocks of the slots,
+// the slots without blocks are filled with the missed or scheduled block of the status 2 or 0
+func NewEpochProposals(epoch uint64, assignments *Assignments, blocks map[uint64]map[string]*Block) *EpochProposals {
+	out := &EpochProposals{Epoch: epoch, Slots: make([]SlotProposal, 0, len(assignments.Assignments))}
+	for i, a := range assignments.Assignments {
+		slot := assignments.FirstSlot + uint64(i)
+		p := SlotProposal{Slot: slot, Proposer: a.Proposer, Status: ProposalScheduled}
+		roots := make([]string, 0, len(blocks[slot]))
+		for root := range blocks[slot] {
+			roots = append(roots, root)
+		}
+		// the status is taken from the block of the first root, unless another one is canonical
+		sort.Strings(roots)
+		for _, root := range roots {
+			block := blocks[slot][root]
+			switch {
+			case block.Status == BlockScheduled || block.Status == BlockMissed:
+				p.Status = ProposalStatus(block.Status)
+			case block.Canonical:
+				p.Status, p.BlockRoot = ProposalProposed, block.BlockRoot
+			case p.Status != ProposalProposed && p.Status != ProposalOrphaned:
+				p.Status, p.BlockRoot = ProposalOrphaned, block.BlockRoot
+			}
+		}
+		out.Slots = append(out.Slots, p)
+	}
+	return out
+}
+
+// Get returns the outcome of the slot
+func (p *EpochProposals) Get(slot uint64) (SlotProposal, bool) {
+	i := sort.Search(len(p.Slots), func(i int) bool { return p.Slots[i].Slot >= slot })
+	if i < len(p.Slots) && p.Slots[i].Slot == slot {
+		return p.Slots[i], true
+	}
+	return SlotProposal{}, false
+}
+
+// Finished tells whether no slot of the epoch is scheduled
+func (p *EpochProposals) Finished() bool {
+	for _, s := range p.Slots {
+		if s.Status == ProposalScheduled {
+			return false
+		}
+	}
+	return true
+}
+
+// ProposerStats counts the outcomes of the proposer duties of the validator
+type ProposerStats struct {
+	Index     uint64 `json:"index"`
+	Assigned  uint64 `json:"assigned"`
+	Proposed  uint64 `json:"proposed"`
+	Missed    uint64 `json:"missed"`
+	Orphaned  uint64 `json:"orphaned"`
+	Scheduled uint64 `json:"scheduled"`
+}
+
+// Count adds the outcome of the duty
+func (s *ProposerStats) Count(status ProposalStatus) {
+	s.Assigned++
+	switch status {
+	case ProposalProposed:
+		s.Proposed++
+	case ProposalMissed:
+		s.Missed++
+	case ProposalOrphaned:
+		s.Orphaned++
+	default:
+		s.Scheduled++
+	}
+}
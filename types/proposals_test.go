@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewEpochProposals(t *testing.T) {
+	assignments := &Assignments{Epoch: 1, FirstSlot: 32, Assignments: []AssignmentSlot{{Proposer: 5}, {Proposer: 6}, {Proposer: 7}, {Proposer: 8}, {Proposer: 9}}}
+	blocks := map[uint64]map[string]*Block{
+		32: {"aa": {Status: 1, Slot: 32, BlockRoot: []byte{0xaa}, Canonical: true}},
+		// the canonical block wins over the orphaned one
+		33: {
+			"01": {Status: 1, Slot: 33, BlockRoot: []byte{0x01}},
+			"bb": {Status: 1, Slot: 33, BlockRoot: []byte{0xbb}, Canonical: true},
+		},
+		34: {"cc": {Status: 1, Slot: 34, BlockRoot: []byte{0xcc}}},
+		35: {"0x0": {Status: 2, Slot: 35, BlockRoot: []byte{0x1}}},
+		36: {"0x0": {Status: 0, Slot: 36, BlockRoot: []byte{0x0}}},
+	}
+
+	proposals := NewEpochProposals(1, assignments, blocks)
+	expected := []struct {
+		status ProposalStatus
+		root   byte
+	}{{ProposalProposed, 0xaa}, {ProposalProposed, 0xbb}, {ProposalOrphaned, 0xcc}, {ProposalMissed, 0}, {ProposalScheduled, 0}}
+	for i, e := range expected {
+		p, ok := proposals.Get(32 + uint64(i))
+		if !ok || p.Status != e.status || p.Proposer != 5+uint64(i) {
+			t.Errorf("slot %d: unexpected proposal %+v", 32+i, p)
+		}
+		if (e.root == 0) != (len(p.BlockRoot) == 0) || (e.root != 0 && p.BlockRoot[0] != e.root) {
+			t.Errorf("slot %d: unexpected block root %x", 32+i, p.BlockRoot)
+		}
+	}
+	if _, ok := proposals.Get(37); ok {
+		t.Error("slot 37 is found")
+	}
+	if proposals.Finished() {
+		t.Error("epoch with the scheduled slot is finished")
+	}
+
+	text, err := json.Marshal(proposals.Slots[2])
+	if err != nil || string(text) != `{"slot":34,"proposer":7,"status":"orphaned","block_root":"zA=="}` {
+		t.Errorf("unexpected JSON %s, %v", text, err)
+	}
+}
+
+func TestProposerStatsCount(t *testing.T) {
+	var s ProposerStats
+	for _, status := range []ProposalStatus{ProposalProposed, ProposalProposed, ProposalMissed, ProposalOrphaned, ProposalScheduled} {
+		s.Count(status)
+	}
+	if s != (ProposerStats{Assigned: 5, Proposed: 2, Missed: 1, Orphaned: 1, Scheduled: 1}) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
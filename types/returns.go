@@ -0,0 +1,53 @@
+package types
+
+import "math"
+
+// Return is the income of the validators over the window, while they were active
+type Return struct {
+	// Key is the validator index, the withdrawal credentials or "network"
+	Key        string `json:"key"`
+	Validators int    `json:"validators"`
+	// StartBalance is the balance at the start of the window or at the activation, Gwei
+	StartBalance uint64 `json:"start_balance"`
+	EndBalance   uint64 `json:"end_balance"`
+	// Deposits are the top-ups after the activation, Gwei
+	Deposits uint64 `json:"deposits"`
+	// Income is the balance change without the top-ups, Gwei
+	Income int64 `json:"income"`
+	// StakeEpochs is the sum of the average effective balance multiplied by the active epochs
+	StakeEpochs float64 `json:"stake_epochs"`
+	APR         float64 `json:"apr"`
+	APY         float64 `json:"apy"`
+}
+
+// Add sums up the returns of the group, the rates are computed again
+func (r *Return) Add(other *Return) {
+	r.Validators += other.Validators
+	r.StartBalance += other.StartBalance
+	r.EndBalance += other.EndBalance
+	r.Deposits += other.Deposits
+	r.Income += other.Income
+	r.StakeEpochs += other.StakeEpochs
+	r.Annualize()
+}
+
+// Annualize computes APR from the income and the stake, APY assumes daily compounding
+func (r *Return) Annualize() {
+	if r.StakeEpochs <= 0 {
+		r.APR, r.APY = 0, 0
+		return
+	}
+	r.APR = float64(r.Income) / r.StakeEpochs * EpochsPerYear
+	r.APY = math.Pow(1+r.APR/365, 365) - 1
+}
+
+// ReturnsReport is the annualized return of the validators over the window of epochs
+type ReturnsReport struct {
+	FromEpoch uint64 `json:"from_epoch"`
+	ToEpoch   uint64 `json:"to_epoch"`
+	// Validators are sorted by the validator index
+	Validators []*Return `json:"validators"`
+	// Credentials are the groups of the validators by the withdrawal credentials, sorted by them
+	Credentials []*Return `json:"credentials"`
+	Network     *Return   `json:"network"`
+}
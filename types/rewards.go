@@ -0,0 +1,74 @@
+package types
+
+import "sort"
+
+// RewardsBreakdown attributes the balance change in Gwei to its sources,
+// rewards are positive, penalties are negative
+type RewardsBreakdown struct {
+	// Proposer is the reward for the inclusion of attestations and slashings into the proposed blocks
+	Proposer int64
+	// Attestation is the reward for the attestations, when they earned more than they lost
+	Attestation int64
+	// Penalties are the losses of the attestations, when they lost more than they earned
+	Penalties int64
+	// Slashing is the initial penalty of the slashed validator
+	Slashing int64
+	// Deposits are the top-ups and the initial deposit of the validator
+	Deposits int64
+}
+
+// Total is the balance change
+func (r *RewardsBreakdown) Total() int64 {
+	return r.Proposer + r.Attestation + r.Penalties + r.Slashing + r.Deposits
+}
+
+// Add sums up the breakdowns
+func (r *RewardsBreakdown) Add(other RewardsBreakdown) {
+	r.Proposer += other.Proposer
+	r.Attestation += other.Attestation
+	r.Penalties += other.Penalties
+	r.Slashing += other.Slashing
+	r.Deposits += other.Deposits
+}
+
+// ValidatorRewards is the balance change of the validator
+type ValidatorRewards struct {
+	Index uint64
+	RewardsBreakdown
+}
+
+// EpochRewards are the balance changes of the validators since the epoch till the next one
+type EpochRewards struct {
+	Epoch uint64
+	// Validators are sorted by the validator index
+	Validators []ValidatorRewards
+	Total      RewardsBreakdown
+}
+
+// Get returns the rewards of the validator, false if its balance was not known
+func (e *EpochRewards) Get(index uint64) (*ValidatorRewards, bool) {
+	return findRewards(e.Validators, index)
+}
+
+// DayRewards sums up the rewards of the epochs of the day since genesis
+type DayRewards struct {
+	Day        uint64
+	FirstEpoch uint64
+	LastEpoch  uint64
+	// Validators are sorted by the validator index
+	Validators []ValidatorRewards
+	Total      RewardsBreakdown
+}
+
+// Get returns the rewards of the validator, false if its balance was not known
+func (d *DayRewards) Get(index uint64) (*ValidatorRewards, bool) {
+	return findRewards(d.Validators, index)
+}
+
+func findRewards(sorted []ValidatorRewards, index uint64) (*ValidatorRewards, bool) {
+	i := sort.Search(len(sorted), func(i int) bool { return sorted[i].Index >= index })
+	if i == len(sorted) || sorted[i].Index != index {
+		return nil, false
+	}
+	return &sorted[i], true
+}
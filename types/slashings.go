@@ -0,0 +1,119 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// SlashingKind is the offence of the slashed validator
+type SlashingKind uint8
+
+const (
+	// SlashingDoubleProposal is the proposal of two blocks at the same slot
+	SlashingDoubleProposal SlashingKind = iota
+	// SlashingDoubleVote is the vote for two targets of the same epoch
+	SlashingDoubleVote
+	// SlashingSurroundVote is the vote, which source and target surround the ones of another vote
+	SlashingSurroundVote
+)
+
+var slashingKindNames = []string{
+	"double_proposal",
+	"double_vote",
+	"surround_vote",
+}
+
+func (k SlashingKind) String() string {
+	if int(k) >= len(slashingKindNames) {
+		return fmt.Sprintf("slashing(%d)", uint8(k))
+	}
+	return slashingKindNames[k]
+}
+
+// ParseSlashingKind returns the kind by its name
+func ParseSlashingKind(name string) (SlashingKind, error) {
+	for i, n := range slashingKindNames {
+		if n == name {
+			return SlashingKind(i), nil
+		}
+	}
+	return SlashingDoubleProposal, fmt.Errorf("unknown slashing kind %q", name)
+}
+
+// MarshalText writes the kind as its name
+func (k SlashingKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText reads the kind from its name
+func (k *SlashingKind) UnmarshalText(text []byte) error {
+	kind, err := ParseSlashingKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// VoteKind tells whether the votes are the double or the surround vote, false if they are not slashable
+func VoteKind(a, b *AttestationData) (SlashingKind, bool) {
+	if a == nil || b == nil || a.Source == nil || a.Target == nil || b.Source == nil || b.Target == nil {
+		return SlashingDoubleVote, false
+	}
+	if a.Target.Epoch == b.Target.Epoch {
+		return SlashingDoubleVote, !a.Equal(b)
+	}
+	surrounds := func(outer, inner *AttestationData) bool {
+		return outer.Source.Epoch < inner.Source.Epoch && inner.Target.Epoch < outer.Target.Epoch
+	}
+	return SlashingSurroundVote, surrounds(a, b) || surrounds(b, a)
+}
+
+// Equal tells whether the votes are the same
+func (d *AttestationData) Equal(other *AttestationData) bool {
+	return d.Slot == other.Slot && d.CommitteeIndex == other.CommitteeIndex &&
+		bytes.Equal(d.BeaconBlockRoot, other.BeaconBlockRoot) &&
+		d.Source.Epoch == other.Source.Epoch && bytes.Equal(d.Source.Root, other.Source.Root) &&
+		d.Target.Epoch == other.Target.Epoch && bytes.Equal(d.Target.Root, other.Target.Root)
+}
+
+// SlashingEvent is the validator slashed by the block
+type SlashingEvent struct {
+	// Slot and BlockRoot are the block including the evidence
+	Slot      uint64       `json:"slot"`
+	BlockRoot []byte       `json:"block_root"`
+	Validator uint64       `json:"validator"`
+	Kind      SlashingKind `json:"kind"`
+	// Whistleblower is the proposer of the block, rewarded for the evidence
+	Whistleblower uint64 `json:"whistleblower"`
+	// the evidence is either the proposer or the attester slashing
+	ProposerSlashing *ProposerSlashing `json:"proposer_slashing,omitempty"`
+	AttesterSlashing *AttesterSlashing `json:"attester_slashing,omitempty"`
+}
+
+// SlashableVote is the attestation of the validator, included by the block
+type SlashableVote struct {
+	Data *AttestationData `json:"data"`
+	// InclusionSlot and BlockRoot are the block, which includes the attestation first
+	InclusionSlot uint64 `json:"inclusion_slot"`
+	BlockRoot     []byte `json:"block_root"`
+}
+
+// SlashableOffence is the pair of the conflicting votes of the validator, found in the included attestations
+type SlashableOffence struct {
+	Validator uint64       `json:"validator"`
+	Kind      SlashingKind `json:"kind"`
+	// Vote1 is the vote seen first
+	Vote1 SlashableVote `json:"vote1"`
+	Vote2 SlashableVote `json:"vote2"`
+}
+
+// SlashingCrossCheck compares the detected offences with the slashings of the network
+type SlashingCrossCheck struct {
+	// Slashed are the validators, which offences are detected and which are slashed
+	Slashed []uint64 `json:"slashed"`
+	// Unslashed are the detected offences of the validators, which are not slashed
+	Unslashed []SlashableOffence `json:"unslashed"`
+	// Undetected are the attester slashings of the validators without the detected offences
+	Undetected []SlashingEvent `json:"undetected"`
+}
@@ -0,0 +1,48 @@
+package types
+
+import "testing"
+
+func TestVoteKind(t *testing.T) {
+	vote := func(source, target uint64, root byte) *AttestationData {
+		return &AttestationData{
+			Slot:            target * 32,
+			BeaconBlockRoot: []byte{root},
+			Source:          &Checkpoint{Epoch: source, Root: []byte{byte(source)}},
+			Target:          &Checkpoint{Epoch: target, Root: []byte{byte(target)}},
+		}
+	}
+	tests := []struct {
+		a, b      *AttestationData
+		kind      SlashingKind
+		slashable bool
+	}{
+		{vote(1, 2, 0xaa), vote(1, 2, 0xaa), SlashingDoubleVote, false},
+		{vote(1, 2, 0xaa), vote(1, 2, 0xbb), SlashingDoubleVote, true},
+		{vote(1, 4, 0xaa), vote(2, 3, 0xaa), SlashingSurroundVote, true},
+		{vote(2, 3, 0xaa), vote(1, 4, 0xaa), SlashingSurroundVote, true},
+		{vote(1, 3, 0xaa), vote(1, 4, 0xaa), SlashingSurroundVote, false},
+		{vote(1, 2, 0xaa), vote(2, 3, 0xaa), SlashingSurroundVote, false},
+	}
+	for i, test := range tests {
+		kind, slashable := VoteKind(test.a, test.b)
+		if slashable != test.slashable || (slashable && kind != test.kind) {
+			t.Errorf("%d: %v %v, expected %v %v", i, kind, slashable, test.kind, test.slashable)
+		}
+	}
+	if _, slashable := VoteKind(vote(1, 2, 0xaa), &AttestationData{}); slashable {
+		t.Error("vote without checkpoints is slashable")
+	}
+}
+
+func TestSlashingKindText(t *testing.T) {
+	for k := SlashingDoubleProposal; k <= SlashingSurroundVote; k++ {
+		text, _ := k.MarshalText()
+		var parsed SlashingKind
+		if err := parsed.UnmarshalText(text); err != nil || parsed != k {
+			t.Errorf("%v is parsed as %v, %v", k, parsed, err)
+		}
+	}
+	if _, err := ParseSlashingKind("surround"); err == nil {
+		t.Error("unknown kind is parsed")
+	}
+}
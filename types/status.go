@@ -0,0 +1,123 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+)
+
+// FarFutureEpoch is the epoch of the validator events that are not scheduled yet
+const FarFutureEpoch = math.MaxUint64
+
+// ValidatorStatus is the state of the validator at the epoch, as defined by the beacon node API
+type ValidatorStatus uint8
+
+const (
+	// StatusUnknown is the status, which was not derived
+	StatusUnknown ValidatorStatus = iota
+	// StatusPendingInitialized is the deposited validator, not yet eligible for activation
+	StatusPendingInitialized
+	// StatusPendingQueued is the validator waiting in the activation queue
+	StatusPendingQueued
+	// StatusActiveOngoing is the active validator without a scheduled exit
+	StatusActiveOngoing
+	// StatusActiveExiting is the active validator with the voluntary exit scheduled
+	StatusActiveExiting
+	// StatusActiveSlashed is the slashed validator, which is not exited yet
+	StatusActiveSlashed
+	// StatusExitedUnslashed is the exited validator, which was not slashed
+	StatusExitedUnslashed
+	// StatusExitedSlashed is the exited validator, which was slashed
+	StatusExitedSlashed
+	// StatusWithdrawalPossible is the exited validator, which balance can be withdrawn
+	StatusWithdrawalPossible
+)
+
+var validatorStatusNames = []string{
+	"",
+	"pending_initialized",
+	"pending_queued",
+	"active_ongoing",
+	"active_exiting",
+	"active_slashed",
+	"exited_unslashed",
+	"exited_slashed",
+	"withdrawal_possible",
+}
+
+func (s ValidatorStatus) String() string {
+	if int(s) >= len(validatorStatusNames) {
+		return fmt.Sprintf("status(%d)", uint8(s))
+	}
+	return validatorStatusNames[s]
+}
+
+// ParseValidatorStatus returns the status by its name
+func ParseValidatorStatus(name string) (ValidatorStatus, error) {
+	for i, n := range validatorStatusNames {
+		if n == name {
+			return ValidatorStatus(i), nil
+		}
+	}
+	return StatusUnknown, fmt.Errorf("unknown validator status %q", name)
+}
+
+// IsActive tells whether the validator is expected to propose and attest
+func (s ValidatorStatus) IsActive() bool {
+	return s == StatusActiveOngoing || s == StatusActiveExiting || s == StatusActiveSlashed
+}
+
+// MarshalText writes the status as its name
+func (s ValidatorStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads the status from its name
+func (s *ValidatorStatus) UnmarshalText(text []byte) error {
+	status, err := ParseValidatorStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// Value stores the status as its name
+func (s ValidatorStatus) Value() (driver.Value, error) {
+	return s.String(), nil
+}
+
+// validatorStatusAt derives the status at the epoch from the epochs of the validator lifecycle
+func validatorStatusAt(epoch uint64, eligibility, activation, exit, withdrawable uint64, slashed bool) ValidatorStatus {
+	switch {
+	case activation > epoch:
+		if eligibility == FarFutureEpoch {
+			return StatusPendingInitialized
+		}
+		return StatusPendingQueued
+	case exit > epoch:
+		if slashed {
+			return StatusActiveSlashed
+		}
+		if exit == FarFutureEpoch {
+			return StatusActiveOngoing
+		}
+		return StatusActiveExiting
+	case withdrawable > epoch:
+		if slashed {
+			return StatusExitedSlashed
+		}
+		return StatusExitedUnslashed
+	}
+	return StatusWithdrawalPossible
+}
+
+// StatusAt returns the status of the validator at the epoch
+func (v *Validator) StatusAt(epoch uint64) ValidatorStatus {
+	return validatorStatusAt(epoch, v.ActivationEligibilityEpoch, v.ActivationEpoch, v.ExitEpoch, v.WithdrawableEpoch, v.Slashed)
+}
+
+// StatusAt returns the status of the validator at the epoch
+func (v *ValidatorF) StatusAt(epoch uint64) ValidatorStatus {
+	return validatorStatusAt(epoch, v.ActivationEligibilityEpoch, v.ActivationEpoch, v.ExitEpoch, v.WithdrawableEpoch, v.Slashed)
+}
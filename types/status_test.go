@@ -0,0 +1,38 @@
+package types
+
+import "testing"
+
+func TestValidatorStatusAt(t *testing.T) {
+	far := uint64(FarFutureEpoch)
+	tests := []struct {
+		v        Validator
+		expected ValidatorStatus
+	}{
+		{Validator{ActivationEligibilityEpoch: far, ActivationEpoch: far, ExitEpoch: far, WithdrawableEpoch: far}, StatusPendingInitialized},
+		{Validator{ActivationEligibilityEpoch: 8, ActivationEpoch: 12, ExitEpoch: far, WithdrawableEpoch: far}, StatusPendingQueued},
+		{Validator{ActivationEpoch: 10, ExitEpoch: far, WithdrawableEpoch: far}, StatusActiveOngoing},
+		{Validator{ActivationEpoch: 10, ExitEpoch: 12, WithdrawableEpoch: 268}, StatusActiveExiting},
+		{Validator{ActivationEpoch: 10, ExitEpoch: 12, WithdrawableEpoch: 8204, Slashed: true}, StatusActiveSlashed},
+		{Validator{ActivationEpoch: 0, ExitEpoch: 10, WithdrawableEpoch: 266}, StatusExitedUnslashed},
+		{Validator{ActivationEpoch: 0, ExitEpoch: 10, WithdrawableEpoch: 8202, Slashed: true}, StatusExitedSlashed},
+		{Validator{ActivationEpoch: 0, ExitEpoch: 5, WithdrawableEpoch: 10}, StatusWithdrawalPossible},
+	}
+	for _, test := range tests {
+		if status := test.v.StatusAt(10); status != test.expected {
+			t.Errorf("%+v: status %v, expected %v", test.v, status, test.expected)
+		}
+	}
+}
+
+func TestValidatorStatusText(t *testing.T) {
+	for s := StatusPendingInitialized; s <= StatusWithdrawalPossible; s++ {
+		text, _ := s.MarshalText()
+		var parsed ValidatorStatus
+		if err := parsed.UnmarshalText(text); err != nil || parsed != s {
+			t.Errorf("%v is parsed as %v, %v", s, parsed, err)
+		}
+	}
+	if _, err := ParseValidatorStatus("active"); err == nil {
+		t.Error("unknown status is parsed")
+	}
+}